@@ -0,0 +1,158 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/gorilla/websocket"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/viper"
+)
+
+// TokenRefresher is called once when backend rejects a token with 401.
+// It should return a new token and persist it.
+var TokenRefresher func() (string, error)
+
+var refreshMu sync.Mutex
+
+// TokenLoader reads the saved token into the token config key. It's called the
+// first time a token is needed, so commands that don't call the api don't
+// unlock the credential store.
+var TokenLoader func() error
+
+var (
+	tokenMu     sync.Mutex
+	tokenLoaded bool
+)
+
+// Token returns the api token, loading it with TokenLoader on first use.
+func Token() string {
+	tokenMu.Lock()
+	defer tokenMu.Unlock()
+	if !tokenLoaded {
+		tokenLoaded = true
+		if TokenLoader != nil {
+			if err := TokenLoader(); err != nil {
+				jww.ERROR.Printf("Error reading token: %s\n", err)
+			}
+		}
+	}
+	return viper.GetString("token")
+}
+
+// SetToken replaces the api token. Saved token isn't loaded after that.
+func SetToken(token string) {
+	tokenMu.Lock()
+	defer tokenMu.Unlock()
+	tokenLoaded = true
+	viper.Set("token", token)
+}
+
+// AuthHeader returns Authorization header value for the current token.
+func AuthHeader() string {
+	return fmt.Sprintf("Bearer %s", Token())
+}
+
+// SetAuthHeader adds Authorization header if there is a token.
+func SetAuthHeader(header http.Header) {
+	if Token() != "" {
+		header.Set("Authorization", AuthHeader())
+	}
+}
+
+// AuthInfo writes Authorization header for go-sdk requests.
+func AuthInfo(req runtime.ClientRequest, reg strfmt.Registry) error {
+	return req.SetHeaderParam("Authorization", AuthHeader())
+}
+
+// refreshToken asks TokenRefresher for a new token unless the token was already
+// changed after the failed request was sent.
+func refreshToken(usedHeader string) bool {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+	if AuthHeader() != usedHeader {
+		return true
+	}
+	if TokenRefresher == nil {
+		return false
+	}
+	if _, err := TokenRefresher(); err != nil {
+		jww.ERROR.Println(err)
+		return false
+	}
+	return true
+}
+
+// authTransport retries authenticated requests once with a new token on 401 response.
+type authTransport struct {
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	usedHeader := req.Header.Get("Authorization")
+	if usedHeader == "" {
+		return t.next.RoundTrip(req)
+	}
+	if err := bufferBody(req); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !refreshToken(usedHeader) {
+		return resp, err
+	}
+	retry := req.WithContext(req.Context())
+	retry.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		retry.Header[k] = v
+	}
+	retry.Header.Set("Authorization", AuthHeader())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return t.next.RoundTrip(retry)
+}
+
+// HTTPClient returns http client for raw api requests.
+// Requests should set Authorization header with SetAuthHeader.
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: roundTripper()}
+}
+
+// DialWebsocket opens authenticated websocket connection.
+// Origin header defaults to api root scheme and host.
+func DialWebsocket(wsURL string, header http.Header) (*websocket.Conn, error) {
+	if header.Get("Origin") == "" {
+		if root, err := url.Parse(viper.GetString("root")); err == nil {
+			header.Set("Origin", root.Scheme+"://"+root.Host)
+		}
+	}
+	SetAuthHeader(header)
+	usedHeader := header.Get("Authorization")
+	conn, resp, err := dialWebsocket(wsURL, header)
+	if err == websocket.ErrBadHandshake && resp != nil && resp.StatusCode == http.StatusUnauthorized &&
+		usedHeader != "" && refreshToken(usedHeader) {
+		SetAuthHeader(header)
+		conn, _, err = dialWebsocket(wsURL, header)
+	}
+	return conn, err
+}
+
+func dialWebsocket(wsURL string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	start := time.Now()
+	dialer := &websocket.Dialer{
+		Proxy:            mustProxyFunc(),
+		TLSClientConfig:  mustTLSConfig(),
+		HandshakeTimeout: 45 * time.Second,
+	}
+	conn, resp, err := dialer.DialContext(rootContext, wsURL, header)
+	traceWebsocket(wsURL, header, resp, err, time.Since(start))
+	return conn, resp, err
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestAuthTransportRefreshesToken(t *testing.T) {
+	var bodies []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	defer viper.Reset()
+	viper.Set("token", "old")
+	refreshed := 0
+	TokenRefresher = func() (string, error) {
+		refreshed++
+		viper.Set("token", "new")
+		return "new", nil
+	}
+	defer func() { TokenRefresher = nil }()
+
+	req, err := http.NewRequest("POST", server.URL, ioutil.NopCloser(strings.NewReader("data")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetAuthHeader(req.Header)
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Wrong status code: %d", resp.StatusCode)
+	}
+	if refreshed != 1 {
+		t.Errorf("Token should be refreshed once, got: %d", refreshed)
+	}
+	if len(bodies) != 2 || bodies[1] != "data" {
+		t.Errorf("Request body should be sent again: %v", bodies)
+	}
+}
+
+func TestAuthTransportSkipsAnonymousRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	TokenRefresher = func() (string, error) {
+		t.Error("Token should not be refreshed for requests without Authorization header")
+		return "", nil
+	}
+	defer func() { TokenRefresher = nil }()
+	resp, err := HTTPClient().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Wrong status code: %d", resp.StatusCode)
+	}
+}
+
+func TestTokenLoadedOnFirstUse(t *testing.T) {
+	defer viper.Reset()
+	tokenLoaded = false
+	loads := 0
+	TokenLoader = func() error {
+		loads++
+		viper.Set("token", "saved")
+		return nil
+	}
+	defer func() { TokenLoader = nil }()
+	if loads != 0 {
+		t.Fatal("Token shouldn't be loaded before it's needed")
+	}
+	if AuthHeader() != "Bearer saved" || Token() != "saved" || loads != 1 {
+		t.Errorf("Token should be loaded once, got %d loads", loads)
+	}
+	SetToken("new")
+	if Token() != "new" || loads != 1 {
+		t.Error("Set token shouldn't be replaced by saved one")
+	}
+
+	tokenLoaded = false
+	SetToken("login")
+	if Token() != "login" || loads != 1 {
+		t.Error("Saved token shouldn't be loaded after SetToken")
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/viper"
+)
+
+// DefaultCacheTTL is how long resolved names are trusted.
+const DefaultCacheTTL = 10 * time.Minute
+
+var cacheMu sync.Mutex
+
+// cacheEntry is a resolved id stored in the name cache file.
+type cacheEntry struct {
+	ID      string    `json:"id"`
+	Expires time.Time `json:"expires"`
+}
+
+// CacheFile returns path of the name cache set with cache_file config key.
+func CacheFile() string {
+	return viper.GetString("cache_file")
+}
+
+// CacheTTL returns lifetime of cached names. Zero disables the cache.
+func CacheTTL() time.Duration {
+	return viper.GetDuration("cache_ttl")
+}
+
+func cacheEnabled() bool {
+	return CacheFile() != "" && CacheTTL() > 0
+}
+
+// cachePrefix scopes cache keys to the backend and namespace.
+func cachePrefix(kind string) string {
+	return strings.Join([]string{strings.TrimRight(viper.GetString("root"), "/"), viper.GetString("namespace"), kind}, "|") + "|"
+}
+
+func cacheKey(kind, scope, name string) string {
+	return cachePrefix(kind) + scope + "|" + name
+}
+
+func readCache() map[string]cacheEntry {
+	entries := make(map[string]cacheEntry)
+	data, err := ioutil.ReadFile(CacheFile())
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		jww.DEBUG.Printf("Ignoring broken name cache: %s\n", err)
+	}
+	return entries
+}
+
+// writeCache replaces the cache file atomically and drops expired entries.
+func writeCache(entries map[string]cacheEntry) {
+	now := time.Now()
+	for key, entry := range entries {
+		if now.After(entry.Expires) {
+			delete(entries, key)
+		}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+	path := CacheFile()
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".threeblades.cache")
+	if err != nil {
+		jww.DEBUG.Printf("Can't write name cache: %s\n", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		jww.DEBUG.Printf("Can't write name cache: %s\n", err)
+	}
+}
+
+// cachedID returns id stored for the key if it hasn't expired.
+func cachedID(key string) (string, bool) {
+	if !cacheEnabled() {
+		return "", false
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	entry, ok := readCache()[key]
+	if !ok || time.Now().After(entry.Expires) {
+		return "", false
+	}
+	return entry.ID, true
+}
+
+func storeID(key, id string) {
+	if !cacheEnabled() {
+		return
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	entries := readCache()
+	entries[key] = cacheEntry{ID: id, Expires: time.Now().Add(CacheTTL())}
+	writeCache(entries)
+}
+
+// updateCache removes entries matching fn and rewrites the file if anything changed.
+func updateCache(remove func(key string, entry cacheEntry) bool) {
+	if CacheFile() == "" {
+		return
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	entries := readCache()
+	changed := false
+	for key, entry := range entries {
+		if remove(key, entry) {
+			delete(entries, key)
+			changed = true
+		}
+	}
+	if changed {
+		writeCache(entries)
+	}
+}
+
+// InvalidateNames drops cached names of the given kinds in the current
+// namespace. Commands call it after creating, renaming or deleting resources.
+func InvalidateNames(kinds ...string) {
+	updateCache(func(key string, _ cacheEntry) bool {
+		for _, kind := range kinds {
+			if strings.HasPrefix(key, cachePrefix(kind)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// forgetIDs drops cached names resolved to any id in the url path.
+func forgetIDs(path string) {
+	segments := make(map[string]bool)
+	for _, segment := range strings.Split(path, "/") {
+		segments[segment] = true
+	}
+	updateCache(func(_ string, entry cacheEntry) bool {
+		return segments[entry.ID]
+	})
+}
+
+// ClearCache removes the name cache file.
+func ClearCache() error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	err := os.Remove(CacheFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// cacheTransport forgets cached ids when backend doesn't know them anymore.
+type cacheTransport struct {
+	next http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusNotFound {
+		forgetIDs(req.URL.Path)
+	}
+	return resp, err
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func setupCache(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "tbs-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("cache_file", filepath.Join(dir, "cache.json"))
+	viper.Set("cache_ttl", time.Minute)
+	viper.Set("root", "http://example.com")
+	viper.Set("namespace", "test")
+	return func() {
+		viper.Reset()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestResolveIDCache(t *testing.T) {
+	defer setupCache(t)()
+	r, calls := staticResolver("Test", "Other")
+	r.Cache = true
+	for i := 0; i < 2; i++ {
+		id, err := r.ResolveID("Test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != "id-test-0" {
+			t.Errorf("Wrong id: %s", id)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("Expected a single list call, got %d", *calls)
+	}
+
+	viper.Set("namespace", "other")
+	if _, ok := r.CachedID("Test"); ok {
+		t.Error("Names shouldn't be shared between namespaces")
+	}
+	viper.Set("namespace", "test")
+
+	InvalidateNames("project")
+	if _, ok := r.CachedID("Test"); ok {
+		t.Error("Name wasn't invalidated")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	defer setupCache(t)()
+	r, calls := staticResolver("Test")
+	r.Cache = true
+	viper.Set("cache_ttl", 0)
+	r.ResolveID("Test")
+	r.ResolveID("Test")
+	if *calls != 2 {
+		t.Errorf("Cache should be disabled with zero ttl, got %d calls", *calls)
+	}
+
+	viper.Set("cache_ttl", time.Minute)
+	key := cacheKey("project", "", "Test")
+	writeCache(map[string]cacheEntry{key: {ID: "old", Expires: time.Now().Add(-time.Second)}})
+	if _, ok := cachedID(key); ok {
+		t.Error("Expired entry was used")
+	}
+}
+
+func TestCacheForgetsMissingIDs(t *testing.T) {
+	defer setupCache(t)()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	storeID(cacheKey("server", "p1", "gone"), "s1")
+	storeID(cacheKey("server", "p1", "kept"), "s2")
+
+	req, _ := http.NewRequest("GET", server.URL+"/v1/test/projects/p1/servers/s1/", nil)
+	resp, err := roundTripper().RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, ok := cachedID(cacheKey("server", "p1", "gone")); ok {
+		t.Error("Missing id wasn't forgotten")
+	}
+	if _, ok := cachedID(cacheKey("server", "p1", "kept")); !ok {
+		t.Error("Unrelated id was forgotten")
+	}
+	if err := ClearCache(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(CacheFile()); !os.IsNotExist(err) {
+		t.Errorf("Cache file wasn't removed: %v", err)
+	}
+}
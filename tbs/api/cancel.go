@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// RequestParams is implemented by every go-sdk params object.
+type RequestParams interface {
+	SetContext(ctx context.Context)
+	SetTimeout(timeout time.Duration)
+}
+
+var rootContext = context.Background()
+
+// SetRootContext sets the context every api request is bound to.
+// It is cancelled when the user interrupts the command.
+func SetRootContext(ctx context.Context) {
+	rootContext = ctx
+}
+
+// RootContext returns the context set with SetRootContext.
+func RootContext() context.Context {
+	return rootContext
+}
+
+// RequestTimeout returns the deadline of a single api call including retries.
+// Zero means no deadline.
+func RequestTimeout() time.Duration {
+	return viper.GetDuration("request_timeout")
+}
+
+// WithContext binds go-sdk params to the root context and --request-timeout.
+func WithContext(params RequestParams) {
+	params.SetContext(rootContext)
+	params.SetTimeout(RequestTimeout())
+}
+
+// RequestContext returns context for raw http requests. It is cancelled with
+// the root context or when --request-timeout expires.
+func RequestContext() (context.Context, context.CancelFunc) {
+	if timeout := RequestTimeout(); timeout > 0 {
+		return context.WithTimeout(rootContext, timeout)
+	}
+	return context.WithCancel(rootContext)
+}
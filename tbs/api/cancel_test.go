@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func hangingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := hangingServer()
+	defer server.Close()
+	defer viper.Reset()
+	viper.Set("root", server.URL)
+	viper.Set("request_timeout", 50*time.Millisecond)
+	start := time.Now()
+	_, err := Client().GetProjectIDByName("Test")
+	var timeout interface{ Timeout() bool }
+	if !errors.As(err, &timeout) || !timeout.Timeout() {
+		t.Errorf("Expected timeout error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Request wasn't aborted in time: %s", elapsed)
+	}
+}
+
+func TestRootContextCancel(t *testing.T) {
+	server := hangingServer()
+	defer server.Close()
+	defer viper.Reset()
+	viper.Set("root", server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	SetRootContext(ctx)
+	defer SetRootContext(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := Client().GetProjectIDByName("Test")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled error, got: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	reqCtx, reqCancel := RequestContext()
+	defer reqCancel()
+	if _, err = HTTPClient().Do(req.WithContext(reqCtx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled error for raw request, got: %v", err)
+	}
+}
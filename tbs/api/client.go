@@ -2,7 +2,11 @@ package api
 
 import (
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/3Blades/cli-tools/tbs/utils"
 	apiclient "github.com/3Blades/go-sdk/client"
@@ -26,21 +30,33 @@ type APIClient struct {
 	AuthInfo  runtime.ClientAuthInfoWriterFunc
 }
 
-func (c *APIClient) GetProjectIDByName(name string) (string, error) {
-	if c.projectID != "" {
-		return c.projectID, nil
-	}
-	params := projects.NewProjectsListParams()
-	params.SetNamespace(c.Namespace)
-	params.SetName(&name)
-	resp, err := c.Projects.ProjectsList(params, c.AuthInfo)
-	if err != nil {
-		return "", err
-	}
-	if len(resp.Payload) == 0 {
-		return "", fmt.Errorf("There is no project with name: '%s'", name)
+// ProjectResolver resolves projects in the namespace by name.
+func (c *APIClient) ProjectResolver() *Resolver {
+	return &Resolver{
+		Kind:  "project",
+		Cache: true,
+		List: func(name string) ([]*Resource, error) {
+			params := projects.NewProjectsListParams()
+			WithContext(params)
+			params.SetNamespace(c.Namespace)
+			if name != "" {
+				params.SetName(&name)
+			}
+			resp, err := c.Projects.ProjectsList(params, c.AuthInfo)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]*Resource, len(resp.Payload))
+			for i, project := range resp.Payload {
+				out[i] = &Resource{ID: project.ID, Name: stringValue(project.Name), Value: project}
+			}
+			return out, nil
+		},
 	}
-	return resp.Payload[0].ID, nil
+}
+
+func (c *APIClient) GetProjectIDByName(name string) (string, error) {
+	return c.ProjectResolver().ResolveID(name)
 }
 
 func (c *APIClient) GetProjectID() (string, error) {
@@ -56,42 +72,84 @@ func (c *APIClient) GetProjectID() (string, error) {
 }
 
 func (c *APIClient) ListServers(ls *utils.ListFlags) ([]*models.Server, error) {
+	servers := []*models.Server{}
+	err := c.ListServerPages(ls, nil, func(page []*models.Server) error {
+		servers = append(servers, page...)
+		return nil
+	})
+	if err != nil {
+		return []*models.Server{}, err
+	}
+	return servers, nil
+}
+
+// ListServerPages calls fn for every page of servers in the current project.
+// Equality filters the backend supports are pushed down, filters may be nil.
+func (c *APIClient) ListServerPages(ls *utils.ListFlags, filters *Filter, fn func([]*models.Server) error) error {
 	params := projects.NewProjectsServersListParams()
-	ls.Apply(params)
+	WithContext(params)
 	params.SetNamespace(c.Namespace)
 	projectID, err := c.GetProjectID()
 	if err != nil {
-		return []*models.Server{}, err
+		return err
 	}
 	params.SetProject(projectID)
-	resp, err := c.Projects.ProjectsServersList(params, c.AuthInfo)
+	filters.PushDown(params)
+	return ls.Pages(params, func() (int, error) {
+		resp, err := c.Projects.ProjectsServersList(params, c.AuthInfo)
+		if err != nil {
+			return 0, err
+		}
+		return len(resp.Payload), fn(resp.Payload)
+	})
+}
+
+// ServerResolver resolves servers in the current project by name.
+func (c *APIClient) ServerResolver() (*Resolver, error) {
+	projectID, err := c.GetProjectID()
 	if err != nil {
-		return []*models.Server{}, err
+		return nil, err
 	}
-	return resp.Payload, nil
+	return &Resolver{
+		Kind:  "server",
+		Cache: true,
+		Scope: projectID,
+		List: func(name string) ([]*Resource, error) {
+			params := projects.NewProjectsServersListParams()
+			WithContext(params)
+			params.SetNamespace(c.Namespace)
+			params.SetProject(projectID)
+			if name != "" {
+				params.SetName(&name)
+			}
+			resp, err := c.Projects.ProjectsServersList(params, c.AuthInfo)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]*Resource, len(resp.Payload))
+			for i, server := range resp.Payload {
+				out[i] = &Resource{ID: server.ID, Name: stringValue(server.Name), Value: server}
+			}
+			return out, nil
+		},
+	}, nil
 }
 
 func (c *APIClient) GetServerByName(name string) (*models.Server, error) {
-	params := projects.NewProjectsServersListParams()
-	params.SetNamespace(c.Namespace)
-	projectID, err := c.GetProjectID()
+	r, err := c.ServerResolver()
 	if err != nil {
 		return nil, err
 	}
-	params.SetProject(projectID)
-	params.SetName(&name)
-	resp, err := c.Projects.ProjectsServersList(params, c.AuthInfo)
+	res, err := r.Resolve(name)
 	if err != nil {
 		return nil, err
 	}
-	if len(resp.Payload) < 1 {
-		return nil, fmt.Errorf("There is no server with name: %s", name)
-	}
-	return resp.Payload[0], nil
+	return res.Value.(*models.Server), nil
 }
 
 func (c *APIClient) GetServerByID(serverID string) (*models.Server, error) {
 	params := projects.NewProjectsServersReadParams()
+	WithContext(params)
 	params.SetNamespace(c.Namespace)
 	params.SetServer(serverID)
 	projectID, err := c.GetProjectID()
@@ -106,38 +164,74 @@ func (c *APIClient) GetServerByID(serverID string) (*models.Server, error) {
 	return resp.Payload, nil
 }
 
-func (c *APIClient) GetHostIDByName(hostName string) (string, error) {
-	params := hosts.NewHostsListParams()
-	params.SetNamespace(c.Namespace)
-	params.SetName(&hostName)
-	resp, err := c.Hosts.HostsList(params, c.AuthInfo)
-	if err != nil {
-		return "", err
+// HostResolver resolves docker hosts in the namespace by name.
+func (c *APIClient) HostResolver() *Resolver {
+	return &Resolver{
+		Kind:  "host",
+		Cache: true,
+		List: func(name string) ([]*Resource, error) {
+			params := hosts.NewHostsListParams()
+			WithContext(params)
+			params.SetNamespace(c.Namespace)
+			if name != "" {
+				params.SetName(&name)
+			}
+			resp, err := c.Hosts.HostsList(params, c.AuthInfo)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]*Resource, len(resp.Payload))
+			for i, host := range resp.Payload {
+				out[i] = &Resource{ID: host.ID, Name: stringValue(host.Name), Value: host}
+			}
+			return out, nil
+		},
 	}
-	if len(resp.Payload) < 1 {
-		return "", fmt.Errorf("There is no host with name: %s", hostName)
+}
+
+func (c *APIClient) GetHostIDByName(hostName string) (string, error) {
+	return c.HostResolver().ResolveID(hostName)
+}
+
+// TriggerResolver resolves triggers of a server by name.
+func (c *APIClient) TriggerResolver(projectID, serverID string) *Resolver {
+	return &Resolver{
+		Kind:  "trigger",
+		Cache: true,
+		Scope: projectID + "/" + serverID,
+		List: func(name string) ([]*Resource, error) {
+			params := projects.NewServiceTriggerListParams()
+			WithContext(params)
+			params.SetNamespace(c.Namespace)
+			params.SetProject(projectID)
+			params.SetServer(serverID)
+			if name != "" {
+				params.SetName(&name)
+			}
+			resp, err := c.Projects.ServiceTriggerList(params, c.AuthInfo)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]*Resource, len(resp.Payload))
+			for i, trigger := range resp.Payload {
+				out[i] = &Resource{ID: trigger.ID, Name: trigger.Name, Value: trigger}
+			}
+			return out, nil
+		},
 	}
-	return resp.Payload[0].ID, nil
 }
 
 func (c *APIClient) GetServerTriggerByName(projectID, serverID, name string) (*models.ServerAction, error) {
-	params := projects.NewServiceTriggerListParams()
-	params.SetNamespace(c.Namespace)
-	params.SetProject(projectID)
-	params.SetServer(serverID)
-	params.SetName(&name)
-	resp, err := c.Projects.ServiceTriggerList(params, c.AuthInfo)
+	res, err := c.TriggerResolver(projectID, serverID).Resolve(name)
 	if err != nil {
 		return nil, err
 	}
-	if len(resp.Payload) < 1 {
-		return nil, fmt.Errorf("There is no trigger with name: %s", name)
-	}
-	return resp.Payload[0], nil
+	return res.Value.(*models.ServerAction), nil
 }
 
 func (c *APIClient) GetServerTriggerByID(projectID, serverID, ID string) (*models.ServerAction, error) {
 	params := projects.NewServiceTriggerReadParams()
+	WithContext(params)
 	params.SetNamespace(c.Namespace)
 	params.SetProject(projectID)
 	params.SetServer(serverID)
@@ -149,6 +243,13 @@ func (c *APIClient) GetServerTriggerByID(projectID, serverID, ID string) (*model
 	return resp.Payload, nil
 }
 
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func Client() *APIClient {
 	cli := apiclient.New(transport(viper.GetString("root")), strfmt.Default)
 	return &APIClient{
@@ -162,14 +263,46 @@ func Client() *APIClient {
 	}
 }
 
-func AuthInfo(req runtime.ClientRequest, reg strfmt.Registry) error {
-	return req.SetHeaderParam("AUTHORIZATION", fmt.Sprintf("Bearer %s", viper.GetString("token")))
-}
-
 func transport(apiRoot string) *httptransport.Runtime {
 	root, err := url.Parse(apiRoot)
 	if err != nil {
 		jww.FATAL.Fatal(err)
 	}
-	return httptransport.New(root.Host, "", []string{root.Scheme})
+	rt := httptransport.New(root.Host, strings.TrimRight(root.Path, "/"), []string{root.Scheme})
+	rt.Transport = roundTripper()
+	return rt
+}
+
+// roundTripper returns http transport shared by go-sdk and raw api requests.
+func roundTripper() http.RoundTripper {
+	var base http.RoundTripper = baseTransport()
+	if level := TraceLevel(); level != TraceOff {
+		base = &traceTransport{next: base, out: traceWriter(), level: level}
+	}
+	retry := &RetryTransport{
+		Next:        base,
+		Retries:     viper.GetInt("retries"),
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		RetryUnsafe: viper.GetBool("retry_unsafe"),
+	}
+	return &errorBodyTransport{next: &cacheTransport{next: &authTransport{next: retry}}}
+}
+
+// baseTransport limits time of a single request attempt with timeout config value.
+func baseTransport() *http.Transport {
+	timeout := viper.GetDuration("timeout")
+	return &http.Transport{
+		Proxy:           mustProxyFunc(),
+		TLSClientConfig: mustTLSConfig(),
+		DialContext: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
 }
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/viper"
+)
+
+// customColumn is a column of custom-columns output.
+type customColumn struct {
+	header string
+	query  *Query
+}
+
+// parseCustomColumns reads columns from "custom-columns=NAME:.name,HOST:.config.host"
+// or from the file given with "custom-columns-file=path". The file has headers
+// on the first line and paths on the second one:
+//
+//	NAME    HOST
+//	.name   .config.host
+func parseCustomColumns(format string) ([]customColumn, error) {
+	parts := strings.SplitN(format, "=", 2)
+	if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+		return nil, &Error{Kind: KindUsage, Message: fmt.Sprintf("Format %s needs columns, e.g. %s=NAME:.name", parts[0], parts[0])}
+	}
+	if parts[0] == "custom-columns-file" {
+		return readColumnsFile(parts[1])
+	}
+	var columns []customColumn
+	for _, spec := range strings.Split(parts[1], ",") {
+		def := strings.SplitN(spec, ":", 2)
+		if len(def) < 2 {
+			return nil, &Error{Kind: KindUsage, Message: fmt.Sprintf("Invalid column '%s', expected HEADER:.path", spec)}
+		}
+		column, err := newCustomColumn(def[0], def[1])
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func readColumnsFile(path string) ([]customColumn, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lines [][]string
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines = append(lines, fields)
+		}
+	}
+	if len(lines) != 2 || len(lines[0]) != len(lines[1]) {
+		return nil, &Error{Kind: KindUsage, Message: fmt.Sprintf("Columns file %s should have a line of headers and a line with the same number of paths", path)}
+	}
+	columns := make([]customColumn, len(lines[0]))
+	for i, header := range lines[0] {
+		if columns[i], err = newCustomColumn(header, lines[1][i]); err != nil {
+			return nil, err
+		}
+	}
+	return columns, nil
+}
+
+func newCustomColumn(header, path string) (customColumn, error) {
+	header, path = strings.TrimSpace(header), strings.TrimSpace(path)
+	if header == "" {
+		return customColumn{}, &Error{Kind: KindUsage, Message: fmt.Sprintf("Column with path '%s' has no header", path)}
+	}
+	query, err := ParseQuery(path)
+	if err != nil {
+		return customColumn{}, err
+	}
+	return customColumn{header: header, query: query}, nil
+}
+
+// CustomColumnsRenderer writes aligned columns selected by paths.
+type CustomColumnsRenderer struct {
+	target  interface{}
+	columns []customColumn
+}
+
+func (c *CustomColumnsRenderer) Render(w io.Writer) error {
+	cw := newColumnsWriter(w, c.columns)
+	if err := cw.RenderPage(c.target); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+// columnsWriter is a page renderer for custom columns. Rows are aligned
+// across all pages, so they are written when the list is closed.
+type columnsWriter struct {
+	tw      *tabwriter.Writer
+	columns []customColumn
+	header  bool
+}
+
+func newColumnsWriter(w io.Writer, columns []customColumn) *columnsWriter {
+	return &columnsWriter{tw: tabwriter.NewWriter(w, 0, 4, 3, ' ', 0), columns: columns}
+}
+
+func (c *columnsWriter) RenderPage(page interface{}) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return writeItems(page, func(item interface{}) error {
+		cells := make([]string, len(c.columns))
+		for i, column := range c.columns {
+			results, err := column.query.Eval(item)
+			if err != nil {
+				return err
+			}
+			values := make([]string, 0, len(results))
+			for _, result := range results {
+				if result != nil {
+					values = append(values, strings.Replace(cellValue(result), "\t", " ", -1))
+				}
+			}
+			cells[i] = strings.Join(values, ",")
+			if len(values) == 0 {
+				cells[i] = "<none>"
+			}
+		}
+		_, err := fmt.Fprintln(c.tw, strings.Join(cells, "\t"))
+		return err
+	})
+}
+
+func (c *columnsWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.tw.Flush()
+}
+
+func (c *columnsWriter) writeHeader() error {
+	if c.header || viper.GetBool("no_headers") {
+		return nil
+	}
+	c.header = true
+	headers := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		headers[i] = column.header
+	}
+	_, err := fmt.Fprintln(c.tw, strings.Join(headers, "\t"))
+	return err
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCustomColumns(t *testing.T) {
+	items := []*formatItem{
+		{ID: "1", Name: "keras", Config: &formatConfig{Script: "main.py"}, Connected: []string{"a", "b"}},
+		{ID: "22", Name: "tf"},
+	}
+	f, err := ioutil.TempFile("", "columns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("ID     SCRIPT\n.id    .config.script\n")
+	f.Close()
+
+	tests := []struct {
+		format   string
+		target   interface{}
+		expected string
+	}{
+		{"custom-columns=NAME:.name,SCRIPT:.config.script", items,
+			"NAME    SCRIPT\nkeras   main.py\ntf      <none>\n"},
+		{"custom-columns=ID:.id,CONNECTED:.connected[]", items[0], "ID   CONNECTED\n1    a,b\n"},
+		{"custom-columns-file=" + f.Name(), items, "ID   SCRIPT\n1    main.py\n22   <none>\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := NewRenderer(test.format, test.target).Render(&buf); err != nil {
+			t.Errorf("%s: %s", test.format, err)
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("Wrong output for '%s':\n%s\nexpected:\n%s", test.format, buf.String(), test.expected)
+		}
+	}
+
+	for _, format := range []string{"custom-columns=", "custom-columns=NAME", "custom-columns=NAME:name", "custom-columns-file=/nonexistent"} {
+		if err := NewRenderer(format, items).Render(&bytes.Buffer{}); err == nil {
+			t.Errorf("Expected error for '%s'", format)
+		}
+	}
+}
+
+func TestWideFormat(t *testing.T) {
+	defer viper.Reset()
+	SetTableFormat("wide_test_format", "table {{.ID}}")
+	viper.Set("wide_test_format", "wide")
+	if format, _ := formatFor("wide_test_format"); format != "table {{.ID}}" {
+		t.Errorf("Wide should fall back to table layout, got: %s", format)
+	}
+	SetWideFormat("wide_test_format", "table {{.ID}}\t{{.Name}}")
+	if format, _ := formatFor("wide_test_format"); format != "table {{.ID}}\t{{.Name}}" {
+		t.Errorf("Wide layout wasn't used, got: %s", format)
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Context holds the connection settings for a single backend.
+type Context struct {
+	Root      string `yaml:"root,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Project   string `yaml:"project,omitempty"`
+	Token     string `yaml:"token,omitempty"`
+}
+
+// Contexts returns every context defined in the config file.
+// Context names are case insensitive.
+func Contexts() (map[string]*Context, error) {
+	contexts := make(map[string]*Context)
+	if err := viper.UnmarshalKey("contexts", &contexts); err != nil {
+		return nil, err
+	}
+	return contexts, nil
+}
+
+// CurrentContextName returns the name of the selected context.
+// The --context flag wins over current-context from the config file.
+func CurrentContextName() string {
+	if name := viper.GetString("context"); name != "" {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(viper.GetString("current-context"))
+}
+
+// CurrentContext returns the selected context or nil if no context is selected.
+func CurrentContext() (*Context, error) {
+	name := CurrentContextName()
+	if name == "" {
+		return nil, nil
+	}
+	contexts, err := Contexts()
+	if err != nil {
+		return nil, err
+	}
+	ctx, ok := contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("There is no context with name: '%s'", name)
+	}
+	if ctx == nil {
+		ctx = &Context{}
+	}
+	return ctx, nil
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCurrentContext(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("contexts", map[string]interface{}{
+		"staging": map[string]interface{}{
+			"root":      "https://staging.example.com",
+			"namespace": "test",
+			"token":     "secret",
+		},
+		"local": map[string]interface{}{
+			"root": "http://localhost:5000",
+		},
+	})
+	viper.Set("current-context", "staging")
+	ctx, err := CurrentContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Root != "https://staging.example.com" || ctx.Namespace != "test" || ctx.Token != "secret" {
+		t.Errorf("Wrong context: %+v", ctx)
+	}
+	viper.Set("context", "LOCAL")
+	ctx, err = CurrentContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Root != "http://localhost:5000" {
+		t.Error("Context flag should win over current-context")
+	}
+	viper.Set("context", "missing")
+	if _, err = CurrentContext(); err == nil {
+		t.Error("Missing context should return an error")
+	}
+}
+
+func TestCurrentContextNotSet(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	ctx, err := CurrentContext()
+	if err != nil {
+		t.Error(err)
+	}
+	if ctx != nil {
+		t.Error("There should be no context when none is selected")
+	}
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+)
+
+// Error kinds. Every kind has its own exit code.
+const (
+	KindError       = "error"
+	KindUsage       = "usage"
+	KindAuth        = "auth"
+	KindNotFound    = "not_found"
+	KindValidation  = "validation"
+	KindConflict    = "conflict"
+	KindNetwork     = "network"
+	KindServer      = "server"
+	KindTimeout     = "timeout"
+	KindAmbiguous   = "ambiguous"
+	KindInterrupted = "interrupted"
+)
+
+var exitCodes = map[string]int{
+	KindError:       1,
+	KindUsage:       2,
+	KindAuth:        3,
+	KindNotFound:    4,
+	KindValidation:  5,
+	KindConflict:    6,
+	KindNetwork:     7,
+	KindServer:      8,
+	KindAmbiguous:   9,
+	KindTimeout:     124,
+	KindInterrupted: 130,
+}
+
+// maxErrorBody limits size of error response bodies kept in memory.
+const maxErrorBody = 1 << 20
+
+var statusPattern = regexp.MustCompile(`\]\[(\d{3})\]`)
+
+// Error is an api failure translated into a readable message.
+type Error struct {
+	Kind    string              `json:"kind"`
+	Status  int                 `json:"status,omitempty"`
+	Message string              `json:"message"`
+	Fields  map[string][]string `json:"fields,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := []string{e.Message}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  %s: %s", name, strings.Join(e.Fields[name], " ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ExitCode returns process exit code for the error kind.
+func (e *Error) ExitCode() int {
+	if code, ok := exitCodes[e.Kind]; ok {
+		return code
+	}
+	return exitCodes[KindError]
+}
+
+// NotFoundError returns error for a resource missing on the backend.
+func NotFoundError(format string, a ...interface{}) error {
+	return &Error{Kind: KindNotFound, Status: http.StatusNotFound, Message: fmt.Sprintf(format, a...)}
+}
+
+// ParseError translates go-sdk, network and context errors into *Error.
+// Any other error is returned with KindError.
+func ParseError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if apiErr, ok := err.(*Error); ok {
+		return apiErr
+	}
+	cause := err
+	if urlErr, ok := err.(*url.Error); ok {
+		cause = urlErr.Err
+	}
+	if cause == context.Canceled {
+		return &Error{Kind: KindInterrupted, Message: "Interrupted"}
+	}
+	if timeout, ok := err.(interface{ Timeout() bool }); ok && timeout.Timeout() {
+		return &Error{Kind: KindTimeout, Message: fmt.Sprintf("Request timed out: %s", err)}
+	}
+	if swaggerErr, ok := err.(*runtime.APIError); ok {
+		var body []byte
+		if resp, ok := swaggerErr.Response.(runtime.ClientResponse); ok && resp.Body() != nil {
+			body, _ = ioutil.ReadAll(io.LimitReader(resp.Body(), maxErrorBody))
+		}
+		return statusError(swaggerErr.Code, body)
+	}
+	if status := responseStatus(err); status != 0 {
+		var body []byte
+		if payload := responsePayload(err); payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		return statusError(status, body)
+	}
+	if _, ok := err.(net.Error); ok {
+		return &Error{Kind: KindNetwork, Message: fmt.Sprintf("Can't connect to api: %s", err)}
+	}
+	return &Error{Kind: KindError, Message: err.Error()}
+}
+
+// ResponseError returns error for raw api responses with error status.
+// Response body is consumed in that case.
+func ResponseError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return statusError(resp.StatusCode, body)
+}
+
+// responseStatus returns status code of go-sdk error responses like
+// *projects.ProjectsCollaboratorsCreateBadRequest.
+func responseStatus(err error) int {
+	if coder, ok := err.(interface{ Code() int }); ok {
+		return coder.Code()
+	}
+	match := statusPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	status, _ := strconv.Atoi(match[1])
+	return status
+}
+
+func responsePayload(err error) interface{} {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	payload := v.FieldByName("Payload")
+	if !payload.IsValid() || !payload.CanInterface() {
+		return nil
+	}
+	return payload.Interface()
+}
+
+// statusError builds error from http status and validation payload.
+func statusError(status int, body []byte) *Error {
+	e := &Error{Status: status}
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		e.Kind = KindAuth
+	case status == http.StatusNotFound:
+		e.Kind = KindNotFound
+	case status == http.StatusConflict:
+		e.Kind = KindConflict
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		e.Kind = KindValidation
+	case status >= 500:
+		e.Kind = KindServer
+	default:
+		e.Kind = KindError
+	}
+	e.Message = statusMessage(status)
+	var payload map[string]interface{}
+	if json.Unmarshal(body, &payload) != nil {
+		return e
+	}
+	var general []string
+	for name, value := range payload {
+		messages := fieldMessages(value)
+		if len(messages) == 0 {
+			continue
+		}
+		switch name {
+		case "detail", "non_field_errors", "message", "error":
+			general = append(general, messages...)
+		default:
+			if e.Fields == nil {
+				e.Fields = make(map[string][]string)
+			}
+			e.Fields[name] = messages
+		}
+	}
+	if len(general) > 0 {
+		sort.Strings(general)
+		e.Message = fmt.Sprintf("%s: %s", e.Message, strings.Join(general, " "))
+	}
+	return e
+}
+
+func statusMessage(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "Invalid request"
+	case http.StatusUnauthorized:
+		return "Authentication failed"
+	case http.StatusForbidden:
+		return "Permission denied"
+	case http.StatusNotFound:
+		return "Not found"
+	case http.StatusConflict:
+		return "Conflict"
+	}
+	if status >= 500 {
+		return fmt.Sprintf("Server error (%d %s)", status, http.StatusText(status))
+	}
+	return fmt.Sprintf("Request failed (%d %s)", status, http.StatusText(status))
+}
+
+func fieldMessages(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			out = append(out, fieldMessages(item)...)
+		}
+		return out
+	case map[string]interface{}:
+		var out []string
+		for name, item := range v {
+			for _, msg := range fieldMessages(item) {
+				out = append(out, fmt.Sprintf("%s: %s", name, msg))
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	return nil
+}
+
+// errorBodyTransport keeps error response bodies readable after go-sdk
+// closes them, so ParseError can show validation messages of undeclared responses.
+type errorBodyTransport struct {
+	next http.RoundTripper
+}
+
+func (t *errorBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode < 400 || resp.Body == nil {
+		return resp, err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/3Blades/go-sdk/client/projects"
+	"github.com/3Blades/go-sdk/models"
+	"github.com/spf13/viper"
+)
+
+func TestParseErrorStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		body     string
+		kind     string
+		exitCode int
+		message  string
+		fields   map[string][]string
+	}{
+		{400, `{"name": ["This field is required."], "non_field_errors": ["Invalid data."]}`, KindValidation, 5,
+			"Invalid request: Invalid data.", map[string][]string{"name": {"This field is required."}}},
+		{401, `{"detail": "Signature has expired."}`, KindAuth, 3, "Authentication failed: Signature has expired.", nil},
+		{404, `{"detail": "Not found."}`, KindNotFound, 4, "Not found: Not found.", nil},
+		{409, ``, KindConflict, 6, "Conflict", nil},
+		{500, `<html></html>`, KindServer, 8, "Server error (500 Internal Server Error)", nil},
+	}
+	defer viper.Reset()
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+		viper.Set("root", server.URL)
+		viper.Set("retries", 0)
+		_, err := Client().GetProjectIDByName("Test")
+		server.Close()
+		e := ParseError(err)
+		if e.Kind != test.kind || e.Status != test.status || e.ExitCode() != test.exitCode {
+			t.Errorf("Wrong error for status %d: %+v", test.status, e)
+		}
+		if e.Message != test.message {
+			t.Errorf("Wrong message for status %d: %s", test.status, e.Message)
+		}
+		if !reflect.DeepEqual(e.Fields, test.fields) {
+			t.Errorf("Wrong fields for status %d: %v", test.status, e.Fields)
+		}
+	}
+}
+
+func TestParseErrorPayload(t *testing.T) {
+	err := &projects.ProjectsCollaboratorsCreateBadRequest{
+		Payload: &models.CollaboratorDataError{Member: []string{"User does not exist."}},
+	}
+	e := ParseError(err)
+	if e.Kind != KindValidation || e.Status != http.StatusBadRequest {
+		t.Errorf("Wrong error: %+v", e)
+	}
+	expected := "Invalid request\n  member: User does not exist."
+	if e.Error() != expected {
+		t.Errorf("Wrong error message: %s", e.Error())
+	}
+}
+
+func TestParseErrorOther(t *testing.T) {
+	tests := map[error]string{
+		errors.New("You must specify name"):                             KindError,
+		NotFoundError("There is no project"):                            KindNotFound,
+		context.Canceled:                                                KindInterrupted,
+		&url.Error{Op: "Get", URL: "/projects/", Err: context.Canceled}: KindInterrupted,
+		context.DeadlineExceeded:                                        KindTimeout,
+	}
+	for err, kind := range tests {
+		if e := ParseError(err); e.Kind != kind || e.Message == "" {
+			t.Errorf("Wrong error for '%s': %+v", err, e)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	defer viper.Reset()
+	viper.Set("root", server.URL)
+	viper.Set("retries", 0)
+	_, err := Client().GetProjectIDByName("Test")
+	if e := ParseError(err); e.Kind != KindNetwork || e.ExitCode() != 7 {
+		t.Errorf("Wrong error for connection failure: %+v", e)
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// stdin is replaced in tests.
+var stdin io.Reader = os.Stdin
+
+var stdinUsed bool
+
+// ReadValue returns flag value s, contents of a file for @path or
+// standard input for @-. Values starting with @@ are taken literally
+// without the first @.
+func ReadValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, "@@"):
+		return s[1:], nil
+	case s == "@-":
+		if stdinUsed {
+			return "", errors.New("Standard input can be read by a single flag only")
+		}
+		stdinUsed = true
+		data, err := ioutil.ReadAll(stdin)
+		return string(data), err
+	case strings.HasPrefix(s, "@"):
+		data, err := ioutil.ReadFile(s[1:])
+		return string(data), err
+	}
+	return s, nil
+}
+
+// NewTextVal returns flag value setting p to the text, file contents
+// for @path or standard input for @-.
+func NewTextVal(p *string) *textValue {
+	return &textValue{p}
+}
+
+type textValue struct {
+	value *string
+}
+
+func (t *textValue) String() string {
+	if t.value == nil {
+		return ""
+	}
+	return *t.value
+}
+
+// Set trims trailing newlines of files, so echo output works as well.
+func (t *textValue) Set(s string) error {
+	text, err := ReadValue(s)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(s, "@") && !strings.HasPrefix(s, "@@") {
+		text = strings.TrimRight(text, "\r\n")
+	}
+	*t.value = text
+	return nil
+}
+
+func (t *textValue) Type() string {
+	return "string"
+}
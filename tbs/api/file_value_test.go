@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withStdin(input string) func() {
+	stdin, stdinUsed = strings.NewReader(input), false
+	return func() {
+		stdin, stdinUsed = os.Stdin, false
+	}
+}
+
+func TestTextValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "start.sh")
+	ioutil.WriteFile(script, []byte("#!/bin/sh\npip install keras\n"), 0600)
+	defer withStdin("from stdin\n")()
+
+	tests := []struct {
+		arg      string
+		expected string
+	}{
+		{"plain text", "plain text"},
+		{"@" + script, "#!/bin/sh\npip install keras"},
+		{"@-", "from stdin"},
+		{"@@handle\n", "@handle\n"},
+	}
+	for _, test := range tests {
+		var value string
+		if err := NewTextVal(&value).Set(test.arg); err != nil {
+			t.Errorf("%s: %s", test.arg, err)
+		}
+		if value != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.arg, test.expected, value)
+		}
+	}
+	var value string
+	if err := NewTextVal(&value).Set("@-"); err == nil {
+		t.Error("Standard input should be read only once")
+	}
+	if err := NewTextVal(&value).Set("@" + filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	payload := filepath.Join(dir, "payload.yaml")
+	ioutil.WriteFile(payload, []byte("text: done\nfields:\n  - 1\n  - {name: a}\n"), 0600)
+	defer withStdin(`{"text": "from stdin"}`)()
+
+	tests := []struct {
+		arg      string
+		expected string
+	}{
+		{`{"text": "inline", "n": 1}`, `{"n":1,"text":"inline"}`},
+		{"text: inline yaml", `{"text":"inline yaml"}`},
+		{"@" + payload, `{"fields":[1,{"name":"a"}],"text":"done"}`},
+		{"@-", `{"text":"from stdin"}`},
+	}
+	for _, test := range tests {
+		v := NewJSONVal()
+		if err := v.Set(test.arg); err != nil {
+			t.Errorf("%s: %s", test.arg, err)
+			continue
+		}
+		data, err := json.Marshal(v.Value)
+		if err != nil {
+			t.Errorf("%s: %s", test.arg, err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.arg, test.expected, data)
+		}
+	}
+	for _, arg := range []string{"just text", "[1, 2]", "{broken"} {
+		if err := NewJSONVal().Set(arg); err == nil {
+			t.Errorf("%s: expected error", arg)
+		}
+	}
+	v := NewJSONVal()
+	v.Set("")
+	if v.Value != nil {
+		t.Error("Empty value should clear payload")
+	}
+}
@@ -3,62 +3,337 @@ package api
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
-func NewFilterVal() *filter {
-	return &filter{changed: false, value: make(map[string]string)}
+// NewFilterVal returns value of the --filter flag of list commands.
+func NewFilterVal() *Filter {
+	return &Filter{}
+}
+
+// Filter is a list of expressions results have to match, e.g.
+// name=test, status!=Running, name~^dev-, created>2018-01-01 or
+// server_size in (small,medium). Fields are json field paths of results.
+type Filter struct {
+	exprs []filterExpr
 }
 
-type filter struct {
-	value   map[string]string
-	changed bool
+type filterExpr struct {
+	source string
+	field  string
+	op     string // =, !=, ~, !~, <, <=, >, >=, in
+	values []string
+	re     *regexp.Regexp
 }
 
-func (f *filter) String() string {
-	out := make([]string, len(f.value))
-	i := 0
-	for k, v := range f.value {
-		out[i] = fmt.Sprintf("%s=%s", k, v)
-		i++
+// filterOps are checked in order, longer operators first.
+var filterOps = []string{"!=", "!~", "<=", ">=", "=", "~", "<", ">"}
+
+var filterField = regexp.MustCompile(`^[A-Za-z0-9_.]+`)
+
+func (f *Filter) String() string {
+	out := make([]string, len(f.exprs))
+	for i, expr := range f.exprs {
+		out[i] = expr.source
 	}
-	return "[" + strings.Join(out, " ") + "]"
+	return strings.Join(out, ",")
 }
 
-func (f *filter) Set(val string) error {
-	ss := strings.Split(val, ",")
-	if len(ss) < 1 {
-		return errors.New("Provide at least one error")
+// Set adds comma separated expressions. Repeated flags add up.
+func (f *Filter) Set(val string) error {
+	parts := splitFilter(val)
+	if len(parts) < 1 {
+		return errors.New("Provide at least one filter")
 	}
-	out := make(map[string]string, len(ss))
-	for _, d := range ss {
-		f := strings.Split(d, "=")
-		if len(f) < 2 {
-			return errors.New("Filter should be like 'name=Test'")
+	for _, part := range parts {
+		expr, err := parseFilterExpr(part)
+		if err != nil {
+			return err
 		}
-		out[f[0]] = f[1]
+		f.exprs = append(f.exprs, expr)
 	}
-	if !f.changed {
-		f.value = out
-	} else {
-		new := make(map[string]string)
-		for k, v := range f.value {
-			new[k] = v
-		}
-		for k, v := range out {
-			new[k] = v
+	return nil
+}
+
+func (f *Filter) Type() string {
+	return "filter"
+}
+
+// Get returns value of an equality filter on the field or nil if there is none.
+func (f *Filter) Get(key string) *string {
+	if f == nil {
+		return nil
+	}
+	for _, expr := range f.exprs {
+		if expr.field == key && expr.op == "=" {
+			val := expr.values[0]
+			return &val
 		}
-		f.value = new
 	}
-	f.changed = true
 	return nil
 }
 
-func (f filter) Type() string {
-	return "filter"
+// PushDown sets query params of equality filters the go-sdk params support,
+// so the backend returns fewer results. Results are still matched client-side.
+func (f *Filter) PushDown(params interface{}) {
+	if p, ok := params.(interface{ SetName(*string) }); ok && f.Get("name") != nil {
+		p.SetName(f.Get("name"))
+	}
+	if p, ok := params.(interface{ SetPrivate(*string) }); ok && f.Get("private") != nil {
+		p.SetPrivate(f.Get("private"))
+	}
+}
+
+// Match reports whether json representation of item matches every expression.
+func (f *Filter) Match(item interface{}) (bool, error) {
+	if f == nil || len(f.exprs) == 0 {
+		return true, nil
+	}
+	value, err := orderedValue(item)
+	if err != nil {
+		return false, err
+	}
+	for _, expr := range f.exprs {
+		if !expr.match(lookup(value, expr.field)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// splitFilter splits expressions on commas outside of quotes and brackets,
+// so lists like in (a,b) and regular expressions like a{1,3} stay whole.
+func splitFilter(val string) []string {
+	var parts []string
+	for _, part := range splitOutside(val, ',') {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// splitOutside splits s on sep outside of quotes and brackets.
+func splitOutside(s string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + len(string(r))
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseFilterExpr(source string) (filterExpr, error) {
+	expr := filterExpr{source: source}
+	expr.field = filterField.FindString(source)
+	if expr.field == "" {
+		return expr, fmt.Errorf("Filter '%s' should start with a field name, e.g. name=test", source)
+	}
+	rest := strings.TrimSpace(source[len(expr.field):])
+	if strings.HasPrefix(rest, "in") && strings.HasPrefix(strings.TrimSpace(rest[2:]), "(") {
+		list := strings.TrimSpace(rest[2:])
+		if !strings.HasSuffix(list, ")") {
+			return expr, fmt.Errorf("Filter '%s' is missing ')'", source)
+		}
+		expr.op = "in"
+		for _, v := range splitFilter(list[1 : len(list)-1]) {
+			expr.values = append(expr.values, unquote(v))
+		}
+		if len(expr.values) == 0 {
+			return expr, fmt.Errorf("Filter '%s' needs at least one value", source)
+		}
+		return expr, nil
+	}
+	for _, op := range filterOps {
+		if strings.HasPrefix(rest, op) {
+			expr.op = op
+			break
+		}
+	}
+	if expr.op == "" {
+		return expr, fmt.Errorf("Filter '%s' should use one of =, !=, ~, !~, <, <=, >, >= or in (...)", source)
+	}
+	value := unquote(strings.TrimSpace(rest[len(expr.op):]))
+	expr.values = []string{value}
+	switch expr.op {
+	case "~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return expr, fmt.Errorf("Filter '%s' has invalid regular expression: %s", source, err)
+		}
+		expr.re = re
+	case "<", "<=", ">", ">=":
+		if _, ok := filterNumber(value); ok {
+			break
+		}
+		if _, ok := toTime(value); !ok {
+			return expr, fmt.Errorf("Filter '%s' compares with '%s', use a number or a date like 2018-01-02 or 2018-01-02T15:04:05Z", source, value)
+		}
+	}
+	return expr, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// match checks a field value. Lists match when any of their items does,
+// != and !~ match when none does.
+func (e filterExpr) match(value interface{}) bool {
+	switch e.op {
+	case "!=":
+		return !filterExpr{op: "=", values: e.values}.match(value)
+	case "!~":
+		return !filterExpr{op: "~", re: e.re}.match(value)
+	}
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if e.match(item) {
+				return true
+			}
+		}
+		return false
+	}
+	switch e.op {
+	case "=", "in":
+		for _, want := range e.values {
+			if filterEqual(value, want) {
+				return true
+			}
+		}
+		return false
+	case "~":
+		if _, ok := value.(yaml.MapSlice); ok {
+			return false
+		}
+		return e.re.MatchString(cellValue(value))
+	}
+	cmp, ok := filterCompare(value, e.values[0])
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+// filterEqual compares numbers by value, strings have to be equal.
+func filterEqual(value interface{}, want string) bool {
+	switch value.(type) {
+	case int64, float64:
+		cmp, ok := filterCompare(value, want)
+		return ok && cmp == 0
+	}
+	return cellValue(value) == want
+}
+
+// filterCompare compares numbers and timestamps in strings, ok is false
+// for other values.
+func filterCompare(value interface{}, want string) (int, bool) {
+	switch value.(type) {
+	case int64, float64:
+		a, _ := filterNumber(cellValue(value))
+		b, ok := filterNumber(want)
+		if !ok {
+			return 0, false
+		}
+		return compareFloats(a, b), true
+	case string:
+		a, ok := toTime(value)
+		if !ok {
+			return 0, false
+		}
+		b, ok := toTime(want)
+		if !ok {
+			return 0, false
+		}
+		return compareTimes(a, b), true
+	}
+	return 0, false
+}
+
+func filterNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// FilterPages passes only results matching the filter to out.
+func FilterPages(out PageRenderer, f *Filter) PageRenderer {
+	if f == nil || len(f.exprs) == 0 {
+		return out
+	}
+	return &filterPageRenderer{next: out, filter: f}
+}
+
+type filterPageRenderer struct {
+	next   PageRenderer
+	filter *Filter
+}
+
+func (r *filterPageRenderer) RenderPage(page interface{}) error {
+	items := []interface{}{}
+	err := writeItems(page, func(item interface{}) error {
+		ok, err := r.filter.Match(item)
+		if ok {
+			items = append(items, item)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return r.next.RenderPage(items)
 }
 
-func (f *filter) Get(key string) *string {
-	val := f.value[key]
-	return &val
+func (r *filterPageRenderer) Close() error {
+	return r.next.Close()
 }
@@ -1,54 +1,176 @@
 package api
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+	"time"
+)
 
 func TestNewFilterVal(t *testing.T) {
-	var i interface{}
-	var f *filter
-	var ok bool
-	i = NewFilterVal()
-	if f, ok = i.(*filter); !ok {
-		t.Error("NewFilterVal returned wrong type")
+	f := NewFilterVal()
+	if len(f.exprs) != 0 {
+		t.Error("New filter val should be empty")
 	}
-	if f.changed != false {
-		t.Error("Changed should be false in new filter val")
+	if ok, _ := f.Match(&formatItem{}); !ok {
+		t.Error("Empty filter should match everything")
 	}
 }
 
-//func TestFilterString(t *testing.T) {
-//	f := NewFilterVal()
-//	vals := "test=1,test2=test"
-//	f.Set(vals)
-//	expectedString := "[test=1 test2=test]"
-//	if f.String() != expectedString {
-//		t.Errorf("Wrong output: %s | %s", f, expectedString)
-//	}
-//}
+func TestFilterString(t *testing.T) {
+	f := NewFilterVal()
+	f.Set("test=1,test2=test")
+	expectedString := "test=1,test2=test"
+	if f.String() != expectedString {
+		t.Errorf("Wrong output: %s | %s", f, expectedString)
+	}
+}
 
 func TestFilterSet(t *testing.T) {
 	f := NewFilterVal()
-	vals := "test=1,test2=test"
-	err := f.Set(vals)
+	err := f.Set("test=1,status in (Running, 'a,b'),name~^a{1,3}$")
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
-	if !f.changed {
-		t.Error("Changed should be true after setting a value")
+	if err = f.Set("port>=8000"); err != nil {
+		t.Fatal(err)
 	}
-	if f.value["test"] != "1" {
-		t.Error("Wrong value for test")
+	if len(f.exprs) != 4 {
+		t.Fatalf("Expected 4 expressions, got %d", len(f.exprs))
 	}
-	if f.value["test2"] != "test" {
-		t.Error("Wrong value for test2")
+	in := f.exprs[1]
+	if in.field != "status" || in.op != "in" || len(in.values) != 2 || in.values[1] != "a,b" {
+		t.Errorf("Wrong in expression: %+v", in)
+	}
+	if f.exprs[2].re == nil || f.exprs[3].op != ">=" {
+		t.Errorf("Wrong expressions: %+v", f.exprs)
+	}
+}
+
+func TestFilterSetErrors(t *testing.T) {
+	for _, val := range []string{"", "=test", "name", "name~(", "created>yesterday", "name in (a,b"} {
+		if err := NewFilterVal().Set(val); err == nil {
+			t.Errorf("%s: expected error", val)
+		}
 	}
 }
 
 func TestFilterGet(t *testing.T) {
 	f := NewFilterVal()
-	vals := "test=1"
-	f.Set(vals)
+	f.Set("test=1,name~x")
 	result := f.Get("test")
-	if *result != "1" {
+	if result == nil || *result != "1" {
 		t.Error("Wrong test value")
 	}
+	if f.Get("name") != nil {
+		t.Error("Only equality filters should be returned")
+	}
+}
+
+type filterParams struct {
+	name, private *string
+}
+
+func (p *filterParams) SetName(v *string)    { p.name = v }
+func (p *filterParams) SetPrivate(v *string) { p.private = v }
+
+func TestFilterPushDown(t *testing.T) {
+	f := NewFilterVal()
+	f.Set("name=test,status=Running")
+	params := &filterParams{}
+	f.PushDown(params)
+	if params.name == nil || *params.name != "test" || params.private != nil {
+		t.Errorf("Wrong params: %+v", params)
+	}
+	var nilFilter *Filter
+	nilFilter.PushDown(params)
+}
+
+type filterItem struct {
+	ID      string      `json:"id"`
+	Code    string      `json:"code"`
+	Name    string      `json:"name"`
+	Port    int64       `json:"port"`
+	Size    string      `json:"size"`
+	Created time.Time   `json:"created"`
+	Tags    []string    `json:"tags"`
+	Config  *formatItem `json:"config"`
+}
+
+func TestFilterMatch(t *testing.T) {
+	item := &filterItem{
+		ID:      "1000",
+		Code:    "007",
+		Name:    "dev-keras",
+		Port:    8888,
+		Size:    "4",
+		Created: time.Date(2018, 3, 4, 10, 0, 0, 0, time.UTC),
+		Tags:    []string{"gpu", "ml"},
+		Config:  &formatItem{Name: "nested"},
+	}
+	tests := []struct {
+		filter   string
+		expected bool
+	}{
+		{"name=dev-keras", true},
+		{"name=keras", false},
+		{"code=7", false},
+		{"id=1e3", false},
+		{"id=1000", true},
+		{"code=007", true},
+		{"name!=keras", true},
+		{"name~^dev-", true},
+		{"name!~^dev-", false},
+		{"name in (tf, dev-keras)", true},
+		{"name in (tf)", false},
+		{"port=8888.0", true},
+		{"port>8000", true},
+		{"port<=8000", false},
+		{"size=4.0", false},
+		{"size>2", false},
+		{"created>2018-01-01", true},
+		{"created<2018-03-04T09:00:00Z", false},
+		{"created>=2018-03-04T10:00:00Z", true},
+		{"tags=ml", true},
+		{"tags!=ml", false},
+		{"tags~^g", true},
+		{"config.name=nested", true},
+		{"config.port<1", true},
+		{"missing=", true},
+		{"missing>1", false},
+		{"name=dev-keras,port>9000", false},
+	}
+	for _, test := range tests {
+		f := NewFilterVal()
+		if err := f.Set(test.filter); err != nil {
+			t.Errorf("%s: %s", test.filter, err)
+			continue
+		}
+		ok, err := f.Match(item)
+		if err != nil {
+			t.Errorf("%s: %s", test.filter, err)
+		}
+		if ok != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.filter, test.expected, ok)
+		}
+	}
+}
+
+func TestFilterPages(t *testing.T) {
+	f := NewFilterVal()
+	f.Set("port>0")
+	var buf bytes.Buffer
+	r := FilterPages(NewPageRenderer("table {{.ID}}", &buf), f)
+	items := []*formatItem{{ID: "1", Port: 80}, {ID: "2"}}
+	if err := r.RenderPage(items); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenderPage(items[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "ID\n1\n" {
+		t.Errorf("Wrong output: %q", buf.String())
+	}
 }
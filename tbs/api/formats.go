@@ -0,0 +1,346 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// YAMLRenderer writes target as yaml with the same field names and order as json.
+type YAMLRenderer struct {
+	target interface{}
+}
+
+func (y *YAMLRenderer) Render(w io.Writer) error {
+	value, err := orderedValue(y.target)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// NDJSONRenderer writes every item of a list as a json object on its own line.
+type NDJSONRenderer struct {
+	target interface{}
+}
+
+func (n *NDJSONRenderer) Render(w io.Writer) error {
+	return writeItems(n.target, json.NewEncoder(w).Encode)
+}
+
+// CSVRenderer writes a row for every item with nested fields flattened
+// to dotted column names, e.g. config.script.
+type CSVRenderer struct {
+	target  interface{}
+	comma   rune
+	columns []string
+}
+
+func (c *CSVRenderer) Render(w io.Writer) error {
+	r := newCSVPageRenderer(w, c.comma, c.columns)
+	if err := r.RenderPage(c.target); err != nil {
+		return err
+	}
+	return r.Close()
+}
+
+// parseColumns returns columns selected with format like "csv id,name,config.script".
+func parseColumns(format string) []string {
+	parts := strings.SplitN(format, " ", 2)
+	if len(parts) < 2 {
+		return nil
+	}
+	var columns []string
+	for _, column := range strings.Split(parts[1], ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// writeItems calls fn for every item of a slice or once for any other target.
+func writeItems(target interface{}, fn func(interface{}) error) error {
+	items := reflect.ValueOf(target)
+	if items.Kind() != reflect.Slice {
+		return fn(target)
+	}
+	for i := 0; i < items.Len(); i++ {
+		if err := fn(items.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type yamlPageRenderer struct {
+	w     io.Writer
+	count int
+}
+
+func (y *yamlPageRenderer) RenderPage(page interface{}) error {
+	return writeItems(page, func(item interface{}) error {
+		value, err := orderedValue(item)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal([]interface{}{value})
+		if err != nil {
+			return err
+		}
+		y.count++
+		_, err = y.w.Write(data)
+		return err
+	})
+}
+
+func (y *yamlPageRenderer) Close() error {
+	if y.count == 0 {
+		_, err := io.WriteString(y.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+type ndjsonPageRenderer struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonPageRenderer) RenderPage(page interface{}) error {
+	return writeItems(page, n.enc.Encode)
+}
+
+func (n *ndjsonPageRenderer) Close() error {
+	return nil
+}
+
+// csvPageRenderer takes columns from the format or from items of the first
+// page. Fields missing in the first page are left out of later rows.
+type csvPageRenderer struct {
+	w       *csv.Writer
+	columns []string
+	header  bool
+}
+
+func newCSVPageRenderer(w io.Writer, comma rune, columns []string) *csvPageRenderer {
+	r := &csvPageRenderer{w: csv.NewWriter(w), columns: columns}
+	r.w.Comma = comma
+	return r
+}
+
+func (c *csvPageRenderer) RenderPage(page interface{}) error {
+	var rows []yaml.MapSlice
+	err := writeItems(page, func(item interface{}) error {
+		value, err := orderedValue(item)
+		if err != nil {
+			return err
+		}
+		row, ok := value.(yaml.MapSlice)
+		if !ok {
+			row = yaml.MapSlice{{Key: "value", Value: value}}
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	// Header is already printed, so columns of later pages follow the first one.
+	if c.columns == nil {
+		c.columns = flatColumns(rows)
+	}
+	if err = c.writeHeader(); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(c.columns))
+		for i, column := range c.columns {
+			record[i] = cellValue(lookup(row, column))
+		}
+		if err = c.w.Write(record); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvPageRenderer) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvPageRenderer) writeHeader() error {
+	if c.header || len(c.columns) == 0 {
+		return nil
+	}
+	c.header = true
+	if viper.GetBool("no_headers") {
+		return nil
+	}
+	return c.w.Write(c.columns)
+}
+
+// flatColumns returns dotted names of leaf fields in order of appearance.
+func flatColumns(rows []yaml.MapSlice) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	var walk func(prefix string, m yaml.MapSlice)
+	walk = func(prefix string, m yaml.MapSlice) {
+		for _, item := range m {
+			name := prefix + fmt.Sprint(item.Key)
+			if nested, ok := item.Value.(yaml.MapSlice); ok && len(nested) > 0 {
+				walk(name+".", nested)
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+	for _, row := range rows {
+		walk("", row)
+	}
+	// drop empty objects, e.g. null config, when other rows have its fields
+	out := columns[:0]
+	for _, column := range columns {
+		nested := false
+		for name := range seen {
+			if strings.HasPrefix(name, column+".") {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			out = append(out, column)
+		}
+	}
+	return out
+}
+
+// lookup returns value at dotted path, or nil if there is none.
+func lookup(value interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(yaml.MapSlice)
+		if !ok {
+			return nil
+		}
+		value = nil
+		for _, item := range m {
+			if fmt.Sprint(item.Key) == key {
+				value = item.Value
+				break
+			}
+		}
+	}
+	return value
+}
+
+// cellValue formats scalars as text and nested values as json.
+func cellValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case yaml.MapSlice, []interface{}:
+		data, _ := json.Marshal(plainValue(v))
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// plainValue converts ordered maps back to maps for json encoding.
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		out := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			out[fmt.Sprint(item.Key)] = plainValue(item.Value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = plainValue(item)
+		}
+		return out
+	}
+	return value
+}
+
+// orderedValue converts target to its json representation keeping field order,
+// objects become yaml.MapSlice.
+func orderedValue(target interface{}) (interface{}, error) {
+	data, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeOrdered(dec)
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			m := yaml.MapSlice{}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err = dec.Token()
+			return m, err
+		}
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = dec.Token()
+		return list, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	}
+	return token, nil
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// TemplateFunc documents a function available in format templates.
+type TemplateFunc struct {
+	Name    string
+	Usage   string
+	Doc     string
+	Example string
+}
+
+// TemplateFuncs lists functions registered on every format template.
+var TemplateFuncs = []TemplateFunc{
+	{"json", "json VALUE", "Value as compact json.", `{{json .Config}}`},
+	{"yaml", "yaml VALUE", "Value as yaml.", `{{yaml .Config}}`},
+	{"upper", "upper STRING", "Upper case string.", `{{upper .Name}}`},
+	{"lower", "lower STRING", "Lower case string.", `{{lower .Name}}`},
+	{"truncate", "truncate LENGTH STRING", "String cut to LENGTH characters, ending with ... when cut.", `{{truncate 20 .Description}}`},
+	{"join", "join SEPARATOR LIST", "Items of a list joined with SEPARATOR.", `{{join ", " .Collaborators}}`},
+	{"humanizeTime", "humanizeTime TIME", "Time relative to now, e.g. 3 hours ago.", `{{humanizeTime .Created}}`},
+	{"since", "since TIME", "Short duration since TIME, e.g. 3h or 2d.", `{{since .Created}}`},
+	{"bytes", "bytes NUMBER", "Size in human readable units, e.g. 1.5 MiB.", `{{bytes .Size}}`},
+	{"default", "default DEFAULT VALUE", "VALUE, or DEFAULT when VALUE is empty.", `{{.Description | default "-"}}`},
+	{"pad", "pad WIDTH STRING", "String padded with spaces to WIDTH, negative WIDTH pads on the left.", `{{pad 10 .Name}}`},
+	{"color", "color COLOR STRING", "String in red, green, yellow, blue, magenta, cyan or bold when stdout is a terminal.", `{{color "green" .Status}}`},
+}
+
+// funcMap returns functions for format templates.
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		"json":         jsonFunc,
+		"yaml":         yamlFunc,
+		"upper":        func(s interface{}) string { return strings.ToUpper(toString(s)) },
+		"lower":        func(s interface{}) string { return strings.ToLower(toString(s)) },
+		"truncate":     truncate,
+		"join":         join,
+		"humanizeTime": humanizeTime,
+		"since":        since,
+		"bytes":        humanizeBytes,
+		"default":      defaultValue,
+		"pad":          pad,
+		"color":        color,
+	}
+}
+
+// toString dereferences pointers like *string of go-sdk models.
+func toString(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+func jsonFunc(v interface{}) (string, error) {
+	value, err := orderedValue(v)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = encodeOrdered(&buf, value)
+	return buf.String(), err
+}
+
+func yamlFunc(v interface{}) (string, error) {
+	value, err := orderedValue(v)
+	if err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(value)
+	return strings.TrimSuffix(string(data), "\n"), err
+}
+
+func truncate(length int, s interface{}) string {
+	runes := []rune(toString(s))
+	if length < 0 || len(runes) <= length {
+		return string(runes)
+	}
+	if length <= 3 {
+		return string(runes[:length])
+	}
+	return string(runes[:length-3]) + "..."
+}
+
+func join(sep string, list interface{}) (string, error) {
+	rv := reflect.ValueOf(list)
+	if !rv.IsValid() {
+		return "", nil
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("join expects a list, got %T", list)
+	}
+	items := make([]string, rv.Len())
+	for i := range items {
+		items[i] = toString(rv.Index(i).Interface())
+	}
+	return strings.Join(items, sep), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// toTime accepts time.Time, types based on it like strfmt.DateTime and RFC 3339 strings.
+func toTime(v interface{}) (time.Time, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return time.Time{}, false
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return time.Time{}, false
+	}
+	if rv.Type().ConvertibleTo(timeType) {
+		t := rv.Convert(timeType).Interface().(time.Time)
+		return t, !t.IsZero()
+	}
+	if rv.Kind() == reflect.String {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, rv.String()); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+func humanizeTime(v interface{}) string {
+	t, ok := toTime(v)
+	if !ok {
+		return toString(v)
+	}
+	d := now().Sub(t)
+	suffix := "ago"
+	if d < 0 {
+		d, suffix = -d, "from now"
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+	n, unit := durationUnits(d, []string{"minute", "hour", "day", "month", "year"})
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s %s", n, unit, suffix)
+}
+
+func since(v interface{}) string {
+	t, ok := toTime(v)
+	if !ok {
+		return toString(v)
+	}
+	d := now().Sub(t)
+	if d < 0 {
+		d = 0
+	}
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+	n, unit := durationUnits(d, []string{"m", "h", "d", "mo", "y"})
+	return fmt.Sprintf("%d%s", n, unit)
+}
+
+// durationUnits returns d in the largest unit of minutes, hours, days, months and years
+// that is at least one. Names are given in that order.
+func durationUnits(d time.Duration, names []string) (int, string) {
+	day := 24 * time.Hour
+	units := []time.Duration{time.Minute, time.Hour, day, 30 * day, 365 * day}
+	i := len(units) - 1
+	for i > 0 && d < units[i] {
+		i--
+	}
+	return int(d / units[i]), names[i]
+}
+
+func humanizeBytes(v interface{}) (string, error) {
+	var size float64
+	if _, err := fmt.Sscan(toString(v), &size); err != nil {
+		return "", fmt.Errorf("bytes expects a number, got %v", v)
+	}
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for ; i < len(units)-1 && (size >= 1024 || size <= -1024); i++ {
+		size /= 1024
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d %s", int64(size), units[i]), nil
+	}
+	return fmt.Sprintf("%.1f %s", size, units[i]), nil
+}
+
+func defaultValue(def, v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr {
+		return def
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		if rv.Len() == 0 {
+			return def
+		}
+	default:
+		if rv.Type().Comparable() && rv.Interface() == reflect.Zero(rv.Type()).Interface() {
+			return def
+		}
+	}
+	return rv.Interface()
+}
+
+func pad(width int, s interface{}) string {
+	str := toString(s)
+	if width < 0 {
+		return fmt.Sprintf("%*s", -width, str)
+	}
+	return fmt.Sprintf("%-*s", width, str)
+}
+
+var colors = map[string]string{
+	"bold":    "1",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+}
+
+// colorEnabled reports whether stdout is a terminal and NO_COLOR isn't set.
+var colorEnabled = func() bool {
+	return os.Getenv("NO_COLOR") == "" && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func color(name string, s interface{}) (string, error) {
+	code, ok := colors[name]
+	if !ok {
+		return "", fmt.Errorf("Unknown color '%s'", name)
+	}
+	str := toString(s)
+	if !colorEnabled() {
+		return str, nil
+	}
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", code, str), nil
+}
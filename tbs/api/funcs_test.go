@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	enabled := colorEnabled
+	defer func() { now, colorEnabled = time.Now, enabled }()
+	now = func() time.Time { return time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC) }
+	colorEnabled = func() bool { return false }
+	name := "keras"
+	data := map[string]interface{}{
+		"Name":      &name,
+		"Nil":       (*string)(nil),
+		"Config":    &formatConfig{Script: "main.py"},
+		"Connected": []string{"a", "b"},
+		"Created":   strfmt.DateTime(time.Date(2018, 3, 10, 9, 0, 0, 0, time.UTC)),
+		"Date":      "2018-03-08T12:00:00Z",
+		"Future":    time.Date(2018, 3, 12, 12, 0, 0, 0, time.UTC),
+		"Size":      int64(1536),
+		"Empty":     "",
+	}
+	tests := []struct {
+		tmpl     string
+		expected string
+	}{
+		{`{{json .Config}}`, `{"script":"main.py"}`},
+		{`{{json .Connected}}`, `["a","b"]`},
+		{`{{yaml .Config}}`, "script: main.py"},
+		{`{{upper .Name}}`, "KERAS"},
+		{`{{lower "KeRas"}}`, "keras"},
+		{`{{truncate 4 .Name}}`, "k..."},
+		{`{{truncate 5 .Name}}`, "keras"},
+		{`{{.Name | truncate 2}}`, "ke"},
+		{`{{join ", " .Connected}}`, "a, b"},
+		{`{{humanizeTime .Created}}`, "3 hours ago"},
+		{`{{humanizeTime .Date}}`, "2 days ago"},
+		{`{{humanizeTime .Future}}`, "2 days from now"},
+		{`{{since .Created}}`, "3h"},
+		{`{{since .Date}}`, "2d"},
+		{`{{bytes .Size}}`, "1.5 KiB"},
+		{`{{bytes 512}}`, "512 B"},
+		{`{{.Empty | default "-"}}`, "-"},
+		{`{{.Nil | default "-"}}`, "-"},
+		{`{{0 | default "-"}}`, "-"},
+		{`{{.Name | default "-"}}`, "keras"},
+		{`[{{pad 7 .Name}}]`, "[keras  ]"},
+		{`[{{pad -7 .Name}}]`, "[  keras]"},
+		{`{{color "green" .Name}}`, "keras"},
+	}
+	for _, test := range tests {
+		tmpl, err := template.New("test").Funcs(funcMap()).Parse(test.tmpl)
+		if err != nil {
+			t.Errorf("%s: %s", test.tmpl, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, data); err != nil {
+			t.Errorf("%s: %s", test.tmpl, err)
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("Wrong output of %s: %q, expected %q", test.tmpl, buf.String(), test.expected)
+		}
+	}
+
+	colorEnabled = func() bool { return true }
+	if out, _ := color("red", "x"); out != "\x1b[31mx\x1b[0m" {
+		t.Errorf("Wrong colored output: %q", out)
+	}
+	if _, err := color("pink", "x"); err == nil {
+		t.Error("Expected error for unknown color")
+	}
+	for _, f := range TemplateFuncs {
+		if _, ok := funcMap()[f.Name]; !ok {
+			t.Errorf("Documented function %s isn't registered", f.Name)
+		}
+	}
+	if len(TemplateFuncs) != len(funcMap()) {
+		t.Error("Every template function should be documented")
+	}
+}
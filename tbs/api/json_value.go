@@ -1,11 +1,15 @@
 package api
 
 import (
-	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
+// NewJSONVal returns flag value decoding json or yaml given inline,
+// in a file with @path or on standard input with @-.
 func NewJSONVal() *jsonValue {
 	value := make(map[string]interface{})
 	return &jsonValue{&value}
@@ -16,24 +20,52 @@ type jsonValue struct {
 }
 
 func (j *jsonValue) String() string {
-	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(j.Value)
+	data, err := json.Marshal(j.Value)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return buf.String()
+	return string(data)
 }
 
 func (j *jsonValue) Set(s string) error {
 	if s == "" {
 		j.Value = nil
 		return nil
-	} else {
-		buf := bytes.NewBufferString(s)
-		return json.NewDecoder(buf).Decode(j.Value)
 	}
+	text, err := ReadValue(s)
+	if err != nil {
+		return err
+	}
+	// yaml is a superset of json, so both are decoded the same way.
+	var value interface{}
+	if err = yaml.Unmarshal([]byte(text), &value); err != nil {
+		return err
+	}
+	value = jsonCompatible(value)
+	if _, ok := value.(map[string]interface{}); !ok {
+		return fmt.Errorf("Value should be an object, got %s", text)
+	}
+	j.Value = value
+	return nil
 }
 
 func (j jsonValue) Type() string {
 	return "json"
 }
+
+// jsonCompatible converts maps decoded from yaml to maps with string keys.
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonCompatible(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,371 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Query is a parsed --query expression. It supports a jq subset:
+// .field, .a.b, .["key"], .[0], .[-1], .[1:3], .[], pipes with |
+// and the length and keys functions. JSONPath expressions like
+// $.items[*].name are accepted too.
+type Query struct {
+	expr  string
+	steps [][]queryOp
+}
+
+type queryOp struct {
+	kind  string // field, index, slice, iterate, length, keys
+	field string
+	from  *int
+	to    *int
+}
+
+// CurrentQuery returns expression set with --query or nil if there is none.
+func CurrentQuery() (*Query, error) {
+	expr := viper.GetString("query")
+	if expr == "" {
+		return nil, nil
+	}
+	return ParseQuery(expr)
+}
+
+// ParseQuery parses query expression.
+func ParseQuery(expr string) (*Query, error) {
+	q := &Query{expr: expr}
+	for _, part := range splitOutside(expr, '|') {
+		ops, err := parseQueryTerm(strings.TrimSpace(part))
+		if err != nil {
+			return nil, &Error{Kind: KindUsage, Message: fmt.Sprintf("Invalid query '%s': %s", expr, err)}
+		}
+		q.steps = append(q.steps, ops)
+	}
+	return q, nil
+}
+
+func parseQueryTerm(term string) ([]queryOp, error) {
+	switch term {
+	case "length", "keys":
+		return []queryOp{{kind: term}}, nil
+	case "":
+		return nil, fmt.Errorf("empty expression")
+	}
+	if strings.HasPrefix(term, "$") {
+		term = "." + strings.TrimPrefix(strings.TrimPrefix(term, "$"), ".")
+	}
+	if !strings.HasPrefix(term, ".") {
+		return nil, fmt.Errorf("expression should start with '.' or '$'")
+	}
+	var ops []queryOp
+	for i := 0; i < len(term); {
+		switch {
+		case term[i] == '.' && i+1 < len(term) && term[i+1] == '[':
+			i++
+		case term[i] == '.':
+			j := i + 1
+			for j < len(term) && (term[j] == '_' || unicode.IsLetter(rune(term[j])) || unicode.IsDigit(rune(term[j]))) {
+				j++
+			}
+			if j == i+1 {
+				if j == len(term) && i == 0 {
+					return ops, nil
+				}
+				return nil, fmt.Errorf("field name expected at position %d", i+1)
+			}
+			ops = append(ops, queryOp{kind: "field", field: term[i+1 : j]})
+			i = j
+		case term[i] == '[':
+			j := strings.IndexByte(term[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("missing ']'")
+			}
+			op, err := parseBracket(strings.TrimSpace(term[i+1 : i+j]))
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, op)
+			i += j + 1
+		default:
+			return nil, fmt.Errorf("unexpected '%c' at position %d", term[i], i)
+		}
+	}
+	return ops, nil
+}
+
+func parseBracket(s string) (queryOp, error) {
+	switch {
+	case s == "" || s == "*":
+		return queryOp{kind: "iterate"}, nil
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'"):
+		if len(s) < 2 || s[len(s)-1] != s[0] {
+			return queryOp{}, fmt.Errorf("unterminated string %s", s)
+		}
+		return queryOp{kind: "field", field: s[1 : len(s)-1]}, nil
+	case strings.Contains(s, ":"):
+		parts := strings.SplitN(s, ":", 2)
+		op := queryOp{kind: "slice"}
+		for i, part := range parts {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return queryOp{}, fmt.Errorf("invalid slice [%s]", s)
+			}
+			if i == 0 {
+				op.from = &n
+			} else {
+				op.to = &n
+			}
+		}
+		return op, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return queryOp{}, fmt.Errorf("invalid index [%s]", s)
+	}
+	return queryOp{kind: "index", from: &n}, nil
+}
+
+// Eval runs the query against json representation of target.
+func (q *Query) Eval(target interface{}) ([]interface{}, error) {
+	value, err := orderedValue(target)
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{value}
+	for _, ops := range q.steps {
+		for _, op := range ops {
+			var next []interface{}
+			for _, v := range values {
+				out, err := op.apply(v)
+				if err != nil {
+					return nil, &Error{Kind: KindError, Message: fmt.Sprintf("Query '%s' failed: %s", q.expr, err)}
+				}
+				next = append(next, out...)
+			}
+			values = next
+		}
+	}
+	return values, nil
+}
+
+func (op queryOp) apply(v interface{}) ([]interface{}, error) {
+	switch op.kind {
+	case "field":
+		if v == nil {
+			return []interface{}{nil}, nil
+		}
+		m, ok := v.(yaml.MapSlice)
+		if !ok {
+			return nil, fmt.Errorf("cannot get field '%s' of %s", op.field, typeName(v))
+		}
+		// Keys in brackets may contain dots, so the key isn't split like in lookup.
+		for _, item := range m {
+			if fmt.Sprint(item.Key) == op.field {
+				return []interface{}{item.Value}, nil
+			}
+		}
+		return []interface{}{nil}, nil
+	case "iterate":
+		switch val := v.(type) {
+		case []interface{}:
+			return val, nil
+		case yaml.MapSlice:
+			out := make([]interface{}, len(val))
+			for i, item := range val {
+				out[i] = item.Value
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+	case "index", "slice":
+		if v == nil {
+			return []interface{}{nil}, nil
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot index %s", typeName(v))
+		}
+		if op.kind == "index" {
+			i := *op.from
+			if i < 0 {
+				i += len(list)
+			}
+			if i < 0 || i >= len(list) {
+				return []interface{}{nil}, nil
+			}
+			return []interface{}{list[i]}, nil
+		}
+		from, to := sliceBound(op.from, 0, len(list)), sliceBound(op.to, len(list), len(list))
+		if from > to {
+			from = to
+		}
+		return []interface{}{list[from:to]}, nil
+	case "length":
+		switch val := v.(type) {
+		case nil:
+			return []interface{}{int64(0)}, nil
+		case string:
+			return []interface{}{int64(len([]rune(val)))}, nil
+		case []interface{}:
+			return []interface{}{int64(len(val))}, nil
+		case yaml.MapSlice:
+			return []interface{}{int64(len(val))}, nil
+		}
+		return nil, fmt.Errorf("%s has no length", typeName(v))
+	case "keys":
+		m, ok := v.(yaml.MapSlice)
+		if !ok {
+			return nil, fmt.Errorf("%s has no keys", typeName(v))
+		}
+		keys := make([]string, len(m))
+		for i, item := range m {
+			keys[i] = fmt.Sprint(item.Key)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, len(keys))
+		for i, key := range keys {
+			out[i] = key
+		}
+		return []interface{}{out}, nil
+	}
+	return nil, fmt.Errorf("unknown operation %s", op.kind)
+}
+
+func sliceBound(bound *int, def, length int) int {
+	if bound == nil {
+		return def
+	}
+	i := *bound
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+	return i
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64, float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case yaml.MapSlice:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// WriteQueryResults prints strings and other scalars raw and objects as json,
+// one result per line.
+func WriteQueryResults(w io.Writer, results []interface{}) error {
+	for _, result := range results {
+		var line []byte
+		switch v := result.(type) {
+		case nil:
+			line = []byte("null")
+		case string:
+			line = []byte(v)
+		case yaml.MapSlice, []interface{}:
+			var compact, indented bytes.Buffer
+			if err := encodeOrdered(&compact, v); err != nil {
+				return err
+			}
+			if err := json.Indent(&indented, compact.Bytes(), "", "    "); err != nil {
+				return err
+			}
+			line = indented.Bytes()
+		default:
+			line = []byte(cellValue(v))
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeOrdered writes json keeping key order of yaml.MapSlice.
+func encodeOrdered(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case yaml.MapSlice:
+		buf.WriteByte('{')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(fmt.Sprint(item.Key))
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := encodeOrdered(buf, item.Value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeOrdered(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
+
+// queryPageRenderer collects every page, the query runs against the whole list.
+type queryPageRenderer struct {
+	w     io.Writer
+	query *Query
+	items []interface{}
+}
+
+func (q *queryPageRenderer) RenderPage(page interface{}) error {
+	return writeItems(page, func(item interface{}) error {
+		q.items = append(q.items, item)
+		return nil
+	})
+}
+
+func (q *queryPageRenderer) Close() error {
+	items := q.items
+	if items == nil {
+		items = []interface{}{}
+	}
+	results, err := q.query.Eval(items)
+	if err != nil {
+		return err
+	}
+	return WriteQueryResults(q.w, results)
+}
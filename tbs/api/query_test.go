@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	items := []*formatItem{
+		{ID: "1", Name: "keras", Config: &formatConfig{Script: "main.py"}, Connected: []string{"a", "b"}, Port: 8888},
+		{ID: "2", Name: "tf", Connected: []string{}},
+	}
+	tests := []struct {
+		query    string
+		target   interface{}
+		expected string
+	}{
+		{".name", items[0], "keras\n"},
+		{".port", items[0], "8888\n"},
+		{".config.script", items[0], "main.py\n"},
+		{".config", items[1], "null\n"},
+		{".missing.field", items[0], "null\n"},
+		{`.["name"]`, items[0], "keras\n"},
+		{".connected[-1]", items[0], "b\n"},
+		{".connected", items[0], "[\n    \"a\",\n    \"b\"\n]\n"},
+		{".config", items[0], "{\n    \"script\": \"main.py\"\n}\n"},
+		{".[].name", items, "keras\ntf\n"},
+		{".[1:] | .[].id", items, "2\n"},
+		{"$[*].id", items, "1\n2\n"},
+		{"$.connected[0]", items[0], "a\n"},
+		{"length", items, "2\n"},
+		{".[0] | keys | .[0]", items, "config\n"},
+		{`.["a|b"] | .[0]`, map[string]interface{}{"a|b": []string{"c"}}, "c\n"},
+		{`.["a.b"]`, map[string]interface{}{"a.b": "dotted", "a": map[string]string{"b": "nested"}}, "dotted\n"},
+		{`.a["b"]`, map[string]interface{}{"a.b": "dotted", "a": map[string]string{"b": "nested"}}, "nested\n"},
+		{".", items[1], "{\n    \"id\": \"2\",\n    \"name\": \"tf\",\n    \"config\": null,\n    \"connected\": [],\n    \"port\": 0\n}\n"},
+	}
+	for _, test := range tests {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		results, err := q.Eval(test.target)
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err = WriteQueryResults(&buf, results); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.expected {
+			t.Errorf("Wrong result for '%s':\n%s\nexpected:\n%s", test.query, buf.String(), test.expected)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, query := range []string{"name", ".a[", ".a[x]", ".a |", ".a..b"} {
+		if _, err := ParseQuery(query); ParseError(err).Kind != KindUsage {
+			t.Errorf("Expected usage error for '%s', got: %v", query, err)
+		}
+	}
+	q, _ := ParseQuery(".name.first")
+	if _, err := q.Eval(&formatItem{Name: "x"}); err == nil {
+		t.Error("Expected error for field of a string")
+	}
+}
+
+func TestQueryPages(t *testing.T) {
+	q, _ := ParseQuery(".[].id")
+	var buf bytes.Buffer
+	r := &queryPageRenderer{w: &buf, query: q}
+	r.RenderPage([]*formatItem{{ID: "1"}})
+	r.RenderPage([]*formatItem{{ID: "2"}})
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1\n2\n" {
+		t.Errorf("Wrong paged result: %q", buf.String())
+	}
+}
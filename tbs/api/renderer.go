@@ -5,25 +5,112 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"regexp"
 	"strings"
+	"text/tabwriter"
 	"text/template"
+	"unicode"
 
 	"github.com/spf13/viper"
 )
 
-func Render(formatName string, target interface{}) error {
-	format := viper.GetString(formatName)
+// tableFormats holds default table layouts used when format is just "table",
+// wideFormats layouts with more columns used for "wide".
+var (
+	tableFormats = make(map[string]string)
+	wideFormats  = make(map[string]string)
+)
+
+// SetTableFormat registers the default table layout for the format config key.
+func SetTableFormat(formatName, format string) {
+	tableFormats[formatName] = format
+}
+
+// SetWideFormat registers the table layout used with "wide" format.
+func SetWideFormat(formatName, format string) {
+	wideFormats[formatName] = format
+}
+
+// formatFor returns output format for the resource config key. --format flag
+// wins over the resource key, which wins over format key for all resources.
+func formatFor(formatName string) (string, error) {
+	format := viper.GetString("output")
+	if format == "" {
+		format = viper.GetString(formatName)
+	}
 	if format == "" {
-		format = "json"
+		format = viper.GetString("format")
+	}
+	switch format {
+	case "":
+		return "json", nil
+	case "wide":
+		if layout, ok := wideFormats[formatName]; ok {
+			return layout, nil
+		}
+		fallthrough
+	case "table":
+		if layout, ok := tableFormats[formatName]; ok {
+			return layout, nil
+		}
+		return "", fmt.Errorf("There is no default table layout, use format like: table {{.ID}}\\t{{.Name}}")
+	}
+	return format, nil
+}
+
+// Render writes target to stdout in format set for the config key, the result
+// of --query when it is set, or only ids in quiet mode.
+func Render(formatName string, target interface{}) error {
+	if capturing != nil {
+		return capturing.add(formatName, target)
+	}
+	return renderTo(os.Stdout, formatName, target)
+}
+
+func renderTo(w io.Writer, formatName string, target interface{}) error {
+	query, err := CurrentQuery()
+	if err != nil {
+		return err
+	}
+	if query != nil {
+		results, err := query.Eval(target)
+		if err != nil {
+			return err
+		}
+		return WriteQueryResults(w, results)
+	}
+	if Quiet() {
+		return writeItems(target, func(item interface{}) error {
+			return writeID(w, item)
+		})
+	}
+	format, err := formatFor(formatName)
+	if err != nil {
+		return err
 	}
 	renderer := NewRenderer(format, target)
-	return renderer.Render(os.Stdout)
+	return renderer.Render(w)
 }
 
 func NewRenderer(format string, target interface{}) Renderer {
-	switch strings.SplitN(format, " ", 2)[0] {
+	switch formatKind(format) {
 	case "json":
 		return &JSONRenderer{target}
+	case "yaml":
+		return &YAMLRenderer{target}
+	case "ndjson":
+		return &NDJSONRenderer{target}
+	case "csv":
+		return &CSVRenderer{target: target, comma: ',', columns: parseColumns(format)}
+	case "tsv":
+		return &CSVRenderer{target: target, comma: '\t', columns: parseColumns(format)}
+	case "custom-columns", "custom-columns-file":
+		columns, err := parseCustomColumns(format)
+		if err != nil {
+			return &errorRenderer{err}
+		}
+		return &CustomColumnsRenderer{target: target, columns: columns}
 	default:
 		return &TableRenderer{
 			target: target,
@@ -32,6 +119,16 @@ func NewRenderer(format string, target interface{}) Renderer {
 	}
 }
 
+// formatKind returns format name without arguments, e.g. "csv" for "csv id,name"
+// and "custom-columns" for "custom-columns=NAME:.name".
+func formatKind(format string) string {
+	kind := strings.SplitN(format, " ", 2)[0]
+	if strings.HasPrefix(kind, "custom-columns") {
+		kind = strings.SplitN(kind, "=", 2)[0]
+	}
+	return kind
+}
+
 type Renderer interface {
 	Render(io.Writer) error
 }
@@ -46,22 +143,313 @@ func (j *JSONRenderer) Render(w io.Writer) error {
 	return enc.Encode(j.target)
 }
 
+// TableRenderer executes template for every item. Formats starting with "table"
+// are written as aligned columns with a header unless no_headers is set.
 type TableRenderer struct {
 	target interface{}
 	format string
 }
 
 func (t *TableRenderer) Render(w io.Writer) error {
-	format := t.processFormat()
-	tmpl, err := template.New("table").Parse(format)
+	tw, err := newTableWriter(w, t.format, viper.GetBool("no_headers"))
 	if err != nil {
 		return err
 	}
-	return tmpl.Execute(w, t.target)
+	if err = tw.write(t.target); err != nil {
+		return err
+	}
+	return tw.flush()
+}
+
+// tableWriter writes template rows, through tabwriter for table formats.
+type tableWriter struct {
+	w    io.Writer
+	tw   *tabwriter.Writer
+	tmpl *template.Template
 }
 
-func (t *TableRenderer) processFormat() string {
-	replacer := strings.NewReplacer("{{", "", "}}", "", ".", "", " ", "")
-	columns := replacer.Replace(t.format)
-	return fmt.Sprintf("%s\n{{ range . }}%s\n{{ end }}", columns, t.format)
+func newTableWriter(w io.Writer, format string, noHeaders bool) (*tableWriter, error) {
+	row, table := parseTableFormat(format)
+	tmpl, err := template.New("table").Funcs(funcMap()).Parse(row)
+	if err != nil {
+		return nil, err
+	}
+	t := &tableWriter{w: w, tmpl: tmpl}
+	if !table {
+		// Templates with tabs printed a header before the table prefix existed.
+		if strings.Contains(row, "\t") && !noHeaders {
+			_, err = fmt.Fprintln(w, legacyHeader(row))
+		}
+		return t, err
+	}
+	t.tw = tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+	t.w = t.tw
+	if noHeaders {
+		return t, nil
+	}
+	_, err = fmt.Fprintln(t.w, strings.Join(tableHeaders(row), "\t"))
+	return t, err
+}
+
+// write renders a single object or every item of a slice.
+func (t *tableWriter) write(target interface{}) error {
+	items := reflect.ValueOf(target)
+	if items.Kind() != reflect.Slice {
+		return t.writeRow(target)
+	}
+	for i := 0; i < items.Len(); i++ {
+		if err := t.writeRow(items.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tableWriter) writeRow(item interface{}) error {
+	if err := t.tmpl.Execute(t.w, item); err != nil {
+		return err
+	}
+	_, err := io.WriteString(t.w, "\n")
+	return err
+}
+
+func (t *tableWriter) flush() error {
+	if t.tw == nil {
+		return nil
+	}
+	return t.tw.Flush()
+}
+
+// parseTableFormat strips "table" prefix and turns escaped tabs and newlines
+// given on the command line into real ones.
+func parseTableFormat(format string) (string, bool) {
+	table := format == "table" || strings.HasPrefix(format, "table ")
+	if table {
+		format = strings.TrimPrefix(strings.TrimPrefix(format, "table"), " ")
+	}
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	return format, table
+}
+
+// legacyHeader is the header of templates without the table prefix,
+// e.g. "{{.ID}}\t{{.Name}}" gives "ID\tName".
+func legacyHeader(row string) string {
+	return strings.NewReplacer("{{", "", "}}", "", ".", "", " ", "").Replace(row)
+}
+
+var fieldPattern = regexp.MustCompile(`(?:\.[A-Za-z_][A-Za-z0-9_]*)+`)
+
+// tableHeaders derives column headers from the first field used in every column,
+// e.g. "{{.ImageName}}" gives "IMAGE NAME" and "{{.Config.Script}}" gives "SCRIPT".
+func tableHeaders(row string) []string {
+	columns := strings.Split(row, "\t")
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		name := strings.Trim(strings.NewReplacer("{{", "", "}}", "").Replace(column), " .")
+		if field := fieldPattern.FindString(column); field != "" {
+			name = field[strings.LastIndex(field, ".")+1:]
+		}
+		headers[i] = strings.ToUpper(strings.Join(splitWords(name), " "))
+	}
+	return headers
+}
+
+// splitWords splits camel case names keeping acronyms together,
+// e.g. LogsURL gives Logs, URL.
+func splitWords(name string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == ' ' }) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			if !unicode.IsUpper(runes[i]) {
+				continue
+			}
+			prev := runes[i-1]
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// PageRenderer writes list results page by page as they are fetched,
+// so long lists don't have to be kept in memory.
+type PageRenderer interface {
+	RenderPage(page interface{}) error
+	Close() error
+}
+
+// RenderPages returns page renderer writing to stdout.
+func RenderPages(formatName string) PageRenderer {
+	if capturing != nil {
+		return &capturePageRenderer{capture: capturing, formatName: formatName}
+	}
+	query, err := CurrentQuery()
+	if err != nil {
+		return &errorRenderer{err}
+	}
+	if query != nil {
+		return &queryPageRenderer{w: os.Stdout, query: query}
+	}
+	if Quiet() {
+		return &quietPageRenderer{w: os.Stdout}
+	}
+	format, err := formatFor(formatName)
+	if err != nil {
+		return &errorRenderer{err}
+	}
+	return NewPageRenderer(format, os.Stdout)
+}
+
+func NewPageRenderer(format string, w io.Writer) PageRenderer {
+	switch formatKind(format) {
+	case "json":
+		return &jsonPageRenderer{w: w}
+	case "yaml":
+		return &yamlPageRenderer{w: w}
+	case "ndjson":
+		return &ndjsonPageRenderer{enc: json.NewEncoder(w)}
+	case "csv":
+		return newCSVPageRenderer(w, ',', parseColumns(format))
+	case "tsv":
+		return newCSVPageRenderer(w, '\t', parseColumns(format))
+	case "custom-columns", "custom-columns-file":
+		columns, err := parseCustomColumns(format)
+		if err != nil {
+			return &errorRenderer{err}
+		}
+		return newColumnsWriter(w, columns)
+	default:
+		return &tablePageRenderer{w: w, format: format}
+	}
+}
+
+// Quiet reports whether --quiet is set. Commands print only ids of resources then.
+func Quiet() bool {
+	return viper.GetBool("quiet")
+}
+
+// idFields are json fields printed in quiet mode, the first one present is used.
+var idFields = []string{"id", "user_id", "name"}
+
+func writeID(w io.Writer, item interface{}) error {
+	value, err := orderedValue(item)
+	if err != nil {
+		return err
+	}
+	for _, field := range idFields {
+		if id := lookup(value, field); id != nil {
+			_, err = fmt.Fprintln(w, cellValue(id))
+			return err
+		}
+	}
+	return fmt.Errorf("Can't print %T in quiet mode, it has no id", item)
+}
+
+// quietPageRenderer writes an id per line.
+type quietPageRenderer struct {
+	w io.Writer
+}
+
+func (q *quietPageRenderer) RenderPage(page interface{}) error {
+	return writeItems(page, func(item interface{}) error {
+		return writeID(q.w, item)
+	})
+}
+
+func (q *quietPageRenderer) Close() error {
+	return nil
+}
+
+// errorRenderer fails when the format is invalid.
+type errorRenderer struct {
+	err error
+}
+
+func (e *errorRenderer) Render(w io.Writer) error {
+	return e.err
+}
+
+func (e *errorRenderer) RenderPage(page interface{}) error {
+	return e.err
+}
+
+func (e *errorRenderer) Close() error {
+	return e.err
+}
+
+// jsonPageRenderer writes the same output as JSONRenderer for the whole list.
+type jsonPageRenderer struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonPageRenderer) RenderPage(page interface{}) error {
+	items := reflect.ValueOf(page)
+	if items.Kind() != reflect.Slice {
+		return fmt.Errorf("Can't render %T as a list", page)
+	}
+	for i := 0; i < items.Len(); i++ {
+		data, err := json.MarshalIndent(items.Index(i).Interface(), "    ", "    ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n    "
+		if j.count == 0 {
+			sep = "[\n    "
+		}
+		if _, err = fmt.Fprintf(j.w, "%s%s", sep, data); err != nil {
+			return err
+		}
+		j.count++
+	}
+	return nil
+}
+
+func (j *jsonPageRenderer) Close() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+// tablePageRenderer writes header once and rows of every page. Table formats
+// are aligned across all pages, so they are written when the list is closed.
+type tablePageRenderer struct {
+	w      io.Writer
+	format string
+	tw     *tableWriter
+}
+
+func (t *tablePageRenderer) RenderPage(page interface{}) error {
+	if err := t.init(); err != nil {
+		return err
+	}
+	return t.tw.write(page)
+}
+
+func (t *tablePageRenderer) Close() error {
+	if err := t.init(); err != nil {
+		return err
+	}
+	return t.tw.flush()
+}
+
+func (t *tablePageRenderer) init() error {
+	if t.tw != nil {
+		return nil
+	}
+	tw, err := newTableWriter(t.w, t.format, viper.GetBool("no_headers"))
+	if err != nil {
+		return err
+	}
+	t.tw = tw
+	return nil
 }
@@ -3,8 +3,11 @@ package api
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/spf13/viper"
 )
 
 func TestNewRenderer(t *testing.T) {
@@ -86,3 +89,200 @@ func TestTableRenderer(t *testing.T) {
 		t.Error("No Int value in output")
 	}
 }
+
+func TestTableAlignment(t *testing.T) {
+	type item struct {
+		ID        string
+		ImageName string
+		LogsURL   string
+	}
+	target := []item{{"1", "keras", "ws://a"}, {"22", "tensorflow-gpu", "ws://b"}}
+	var buf bytes.Buffer
+	if err := NewRenderer(`table {{.ID}}\t{{.ImageName}}\t{{ .LogsURL }}`, target).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "ID   IMAGE NAME       LOGS URL\n" +
+		"1    keras            ws://a\n" +
+		"22   tensorflow-gpu   ws://b\n"
+	if buf.String() != expected {
+		t.Errorf("Wrong table:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	viper.Set("no_headers", true)
+	defer viper.Reset()
+	if err := NewRenderer("table {{.ID}}", target[0]).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1\n" {
+		t.Errorf("Single object should be rendered without headers, got: %q", buf.String())
+	}
+}
+
+func TestTableHeaders(t *testing.T) {
+	headers := tableHeaders("{{.ID}}\t{{.IPAddress}}\t{{.Config.Script}}\t{{.Last4}}\t{{.exp_month}}\t{{ printf \"%d\" .ExpYear }}")
+	expected := []string{"ID", "IP ADDRESS", "SCRIPT", "LAST4", "EXP MONTH", "EXP YEAR"}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Errorf("Wrong headers: %v", headers)
+	}
+}
+
+func TestDefaultTableFormat(t *testing.T) {
+	defer viper.Reset()
+	SetTableFormat("test_format", "table {{.ID}}")
+	viper.Set("test_format", "table")
+	if format, err := formatFor("test_format"); err != nil || format != "table {{.ID}}" {
+		t.Errorf("Default layout wasn't used: %s, %v", format, err)
+	}
+	viper.Set("other_format", "table")
+	if _, err := formatFor("other_format"); err == nil {
+		t.Error("Expected error for missing default layout")
+	}
+}
+
+func TestPageRenderer(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	pages := [][]item{{{"a"}, {"b"}}, {}, {{"c"}}}
+	all := append(append([]item{}, pages[0]...), pages[2]...)
+	formats := []string{"json", "yaml", "ndjson", "csv", "tsv name", "{{.Name}}", "table {{.Name}}\t{{.Name}}"}
+	for _, format := range formats {
+		var expected, buf bytes.Buffer
+		if err := NewRenderer(format, all).Render(&expected); err != nil {
+			t.Fatal(err)
+		}
+		r := NewPageRenderer(format, &buf)
+		for _, page := range pages {
+			if err := r.RenderPage(page); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != expected.String() {
+			t.Errorf("Paged output differs for format '%s':\n%s\nexpected:\n%s", format, buf.String(), expected.String())
+		}
+	}
+
+	for format, expected := range map[string]string{"json": "[]\n", "yaml": "[]\n", "ndjson": "", "csv": ""} {
+		var buf bytes.Buffer
+		r := NewPageRenderer(format, &buf)
+		r.Close()
+		if buf.String() != expected {
+			t.Errorf("Wrong %s output for empty list: %q", format, buf.String())
+		}
+	}
+}
+
+type formatConfig struct {
+	Script string `json:"script"`
+}
+
+type formatItem struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Config    *formatConfig `json:"config"`
+	Connected []string      `json:"connected"`
+	Port      int64         `json:"port"`
+}
+
+func TestDataFormats(t *testing.T) {
+	items := []*formatItem{
+		{ID: "1", Name: "keras", Config: &formatConfig{Script: "main.py"}, Connected: []string{"a", "b"}, Port: 8888},
+		{ID: "2", Name: "with, comma", Connected: []string{}},
+	}
+	tests := []struct {
+		format   string
+		target   interface{}
+		expected string
+	}{
+		{"yaml", items[0], "id: \"1\"\nname: keras\nconfig:\n  script: main.py\nconnected:\n- a\n- b\nport: 8888\n"},
+		{"yaml", items, "- id: \"1\"\n  name: keras\n  config:\n    script: main.py\n  connected:\n  - a\n  - b\n  port: 8888\n" +
+			"- id: \"2\"\n  name: with, comma\n  config: null\n  connected: []\n  port: 0\n"},
+		{"ndjson", items[0], `{"id":"1","name":"keras","config":{"script":"main.py"},"connected":["a","b"],"port":8888}` + "\n"},
+		{"ndjson", items, `{"id":"1","name":"keras","config":{"script":"main.py"},"connected":["a","b"],"port":8888}` + "\n" +
+			`{"id":"2","name":"with, comma","config":null,"connected":[],"port":0}` + "\n"},
+		{"csv", items, "id,name,config.script,connected,port\n" +
+			"1,keras,main.py,\"[\"\"a\"\",\"\"b\"\"]\",8888\n" +
+			"2,\"with, comma\",,[],0\n"},
+		{"csv name, config.script,missing", items, "name,config.script,missing\nkeras,main.py,\n\"with, comma\",,\n"},
+		{"csv config", items[0], "config\n\"{\"\"script\"\":\"\"main.py\"\"}\"\n"},
+		{"tsv id,name", items, "id\tname\n1\tkeras\n2\twith, comma\n"},
+		{"csv id", []*formatItem{}, "id\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := NewRenderer(test.format, test.target).Render(&buf); err != nil {
+			t.Errorf("%s: %s", test.format, err)
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("Wrong output for '%s':\n%s\nexpected:\n%s", test.format, buf.String(), test.expected)
+		}
+	}
+}
+
+func TestFormatPrecedence(t *testing.T) {
+	defer viper.Reset()
+	if format, _ := formatFor("project_format"); format != "json" {
+		t.Errorf("Default format should be json, got: %s", format)
+	}
+	viper.Set("format", "yaml")
+	if format, _ := formatFor("project_format"); format != "yaml" {
+		t.Errorf("Format key should apply to every resource, got: %s", format)
+	}
+	viper.Set("project_format", "csv")
+	if format, _ := formatFor("project_format"); format != "csv" {
+		t.Errorf("Resource format should win over format key, got: %s", format)
+	}
+	viper.Set("output", "ndjson")
+	if format, _ := formatFor("project_format"); format != "ndjson" {
+		t.Errorf("--format flag should win, got: %s", format)
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	items := []*formatItem{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}}
+	var buf bytes.Buffer
+	r := &quietPageRenderer{w: &buf}
+	r.RenderPage(items)
+	r.RenderPage(items[:1])
+	if buf.String() != "1\n2\n1\n" {
+		t.Errorf("Wrong ids: %q", buf.String())
+	}
+
+	buf.Reset()
+	named := []struct {
+		Name string `json:"name"`
+	}{{"ctx"}}
+	if err := writeID(&buf, named[0]); err != nil || buf.String() != "ctx\n" {
+		t.Errorf("Name should be used without id, got %q, %v", buf.String(), err)
+	}
+	if err := writeID(&buf, struct{ Other string }{}); err == nil {
+		t.Error("Expected error for object without id")
+	}
+}
+
+func TestLegacyTemplateHeader(t *testing.T) {
+	type item struct {
+		ID   string
+		Name string
+	}
+	target := []item{{"1", "keras"}}
+	var buf bytes.Buffer
+	if err := NewRenderer(`{{.ID}}\t{{ .Name }}`, target).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "ID\tName\n1\tkeras\n" {
+		t.Errorf("Template with tabs should keep its header, got: %q", buf.String())
+	}
+	buf.Reset()
+	if err := NewRenderer("{{.ID}} {{.Name}}", target).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1 keras\n" {
+		t.Errorf("Template without tabs shouldn't have a header, got: %q", buf.String())
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/3Blades/cli-tools/tbs/utils"
+)
+
+// maxSuggestions limits number of names offered when lookup fails.
+const maxSuggestions = 3
+
+// Resource is a listed api object used for name resolution.
+type Resource struct {
+	ID    string
+	Name  string
+	Value interface{}
+}
+
+// Resolver looks up resources of a single kind by id or name.
+type Resolver struct {
+	// Kind is used in error messages, e.g. "project".
+	Kind string
+	// List returns resources filtered by name, or all resources for empty name.
+	// Filtering is optional, names are compared again by the resolver.
+	List func(name string) ([]*Resource, error)
+	// Cache enables the on-disk name cache for ResolveID.
+	Cache bool
+	// Scope separates cached names of nested resources, e.g. servers of a project.
+	Scope string
+}
+
+// Resolve returns the resource with exactly the given name. It fails when
+// several resources share the name and suggests similar names when none matches.
+func (r *Resolver) Resolve(name string) (*Resource, error) {
+	candidates, err := r.List(name)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*Resource
+	for _, res := range candidates {
+		if res.Name == name {
+			matches = append(matches, res)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		if r.Cache {
+			storeID(cacheKey(r.Kind, r.Scope, name), matches[0].ID)
+		}
+		return matches[0], nil
+	case 0:
+		return nil, r.notFound(name)
+	}
+	ids := make([]string, len(matches))
+	for i, res := range matches {
+		ids[i] = res.ID
+	}
+	return nil, &Error{
+		Kind: KindAmbiguous,
+		Message: fmt.Sprintf("There are %d %ss with name '%s', use id instead: %s",
+			len(matches), r.Kind, name, strings.Join(ids, ", ")),
+	}
+}
+
+// ResolveID returns nameOrID as is when it is a UUID, otherwise it resolves the name.
+// Names are looked up in the name cache first when it is enabled for the resolver.
+func (r *Resolver) ResolveID(nameOrID string) (string, error) {
+	if utils.IsUUID(nameOrID) {
+		return nameOrID, nil
+	}
+	if id, ok := r.CachedID(nameOrID); ok {
+		return id, nil
+	}
+	res, err := r.Resolve(nameOrID)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// CachedID returns id of the name from the name cache.
+func (r *Resolver) CachedID(name string) (string, bool) {
+	if !r.Cache {
+		return "", false
+	}
+	return cachedID(cacheKey(r.Kind, r.Scope, name))
+}
+
+func (r *Resolver) notFound(name string) error {
+	msg := fmt.Sprintf("There is no %s with name: '%s'", r.Kind, name)
+	all, err := r.List("")
+	if err != nil {
+		return NotFoundError("%s", msg)
+	}
+	names := make([]string, len(all))
+	for i, res := range all {
+		names[i] = res.Name
+	}
+	if suggestions := suggest(name, names); len(suggestions) > 0 {
+		msg = fmt.Sprintf("%s. Did you mean: %s?", msg, strings.Join(suggestions, ", "))
+	}
+	return NotFoundError("%s", msg)
+}
+
+// suggest returns names similar to name, closest first.
+func suggest(name string, names []string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	lower := strings.ToLower(name)
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, n := range names {
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		l := strings.ToLower(n)
+		distance := levenshtein(lower, l)
+		if distance <= maxDistance || strings.Contains(l, lower) || strings.Contains(lower, l) {
+			candidates = append(candidates, candidate{n, distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	var out []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		out = append(out, candidates[i].name)
+	}
+	return out
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+func staticResolver(names ...string) (*Resolver, *int) {
+	calls := 0
+	return &Resolver{
+		Kind: "project",
+		List: func(string) ([]*Resource, error) {
+			calls++
+			out := make([]*Resource, len(names))
+			for i, name := range names {
+				out[i] = &Resource{ID: "id-" + strings.ToLower(name) + "-" + string('0'+rune(i)), Name: name}
+			}
+			return out, nil
+		},
+	}, &calls
+}
+
+func TestResolve(t *testing.T) {
+	r, _ := staticResolver("Test", "Other", "test")
+	res, err := r.Resolve("Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "id-test-0" {
+		t.Errorf("Wrong resource: %+v", res)
+	}
+
+	r, _ = staticResolver("Test", "Test")
+	_, err = r.Resolve("Test")
+	if e := ParseError(err); e.Kind != KindAmbiguous || !strings.Contains(e.Message, "id-test-0, id-test-1") {
+		t.Errorf("Expected ambiguous error with ids, got: %v", err)
+	}
+
+	r, _ = staticResolver("experiments", "experiment-2", "production")
+	_, err = r.Resolve("experimnts")
+	e := ParseError(err)
+	if e.Kind != KindNotFound {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+	expected := "There is no project with name: 'experimnts'. Did you mean: experiments, experiment-2?"
+	if e.Message != expected {
+		t.Errorf("Wrong message: %s", e.Message)
+	}
+}
+
+func TestResolveID(t *testing.T) {
+	r, calls := staticResolver("Test")
+	id := uuid.NewV4().String()
+	result, err := r.ResolveID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != id || *calls != 0 {
+		t.Error("UUID should be returned without lookup")
+	}
+	if result, _ = r.ResolveID("Test"); result != "id-test-0" {
+		t.Errorf("Wrong id: %s", result)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"notebook", "notebooks", "note", "jupyter", "Notebook-gpu", "rstudio"}
+	tests := map[string][]string{
+		"notebok": {"notebook", "notebooks", "note"},
+		"jupiter": {"jupyter"},
+		"xyz":     nil,
+	}
+	for name, expected := range tests {
+		if result := suggest(name, names); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Wrong suggestions for '%s': %v", name, result)
+		}
+	}
+}
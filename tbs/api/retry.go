@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// RetryTransport retries failed requests with exponential backoff and jitter.
+// Only idempotent requests are retried unless RetryUnsafe is set.
+type RetryTransport struct {
+	Next        http.RoundTripper
+	Retries     int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	RetryUnsafe bool
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Retries < 1 || !(t.RetryUnsafe || isIdempotent(req.Method)) {
+		return t.Next.RoundTrip(req)
+	}
+	if err := bufferBody(req); err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Next.RoundTrip(req)
+		if attempt >= t.Retries || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			resp.Body.Close()
+			jww.INFO.Printf("%s %s: %s, retrying in %s\n", req.Method, req.URL, resp.Status, wait)
+		} else {
+			jww.INFO.Printf("%s %s: %s, retrying in %s\n", req.Method, req.URL, err, wait)
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	backoff := t.MinBackoff << uint(attempt)
+	if backoff > t.MaxBackoff || backoff <= 0 {
+		backoff = t.MaxBackoff
+	}
+	half := int64(backoff / 2)
+	if half <= 0 {
+		return backoff
+	}
+	return time.Duration(half + rand.Int63n(half))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE", "TRACE":
+		return true
+	}
+	return false
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses Retry-After header of 429 and 503 responses.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// bufferBody reads request body into memory so the request can be sent again.
+func bufferBody(req *http.Request) error {
+	if req.Body == nil || req.GetBody != nil {
+		return nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func runFlakyServer(failures int, status int) (*httptest.Server, *int) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}
+	return httptest.NewServer(http.HandlerFunc(handler)), &requests
+}
+
+func retryClient(retries int, unsafe bool) *http.Client {
+	return &http.Client{Transport: &RetryTransport{
+		Next:        http.DefaultTransport,
+		Retries:     retries,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+		RetryUnsafe: unsafe,
+	}}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		failures int
+		status   int
+		retries  int
+		unsafe   bool
+		expected int
+		requests int
+	}{
+		{"retries bad gateway", "GET", 2, http.StatusBadGateway, 3, false, http.StatusOK, 3},
+		{"gives up after retries", "GET", 5, http.StatusServiceUnavailable, 2, false, http.StatusServiceUnavailable, 3},
+		{"does not retry client errors", "GET", 1, http.StatusBadRequest, 3, false, http.StatusBadRequest, 1},
+		{"does not retry post", "POST", 1, http.StatusBadGateway, 3, false, http.StatusBadGateway, 1},
+		{"retries post when unsafe", "POST", 1, http.StatusBadGateway, 3, true, http.StatusOK, 2},
+		{"retries put", "PUT", 1, http.StatusGatewayTimeout, 3, false, http.StatusOK, 2},
+		{"retries too many requests", "GET", 1, http.StatusTooManyRequests, 1, false, http.StatusOK, 2},
+	}
+	for _, test := range tests {
+		server, requests := runFlakyServer(test.failures, test.status)
+		req, err := http.NewRequest(test.method, server.URL, ioutil.NopCloser(strings.NewReader("data")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := retryClient(test.retries, test.unsafe).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		server.Close()
+		if resp.StatusCode != test.expected {
+			t.Errorf("%s: wrong status code %d", test.name, resp.StatusCode)
+		}
+		if *requests != test.requests {
+			t.Errorf("%s: wrong number of requests %d", test.name, *requests)
+		}
+		if resp.StatusCode == http.StatusOK && string(body) != "data" {
+			t.Errorf("%s: request body should be sent again, got '%s'", test.name, body)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "2")
+	if wait, ok := retryAfter(resp); !ok || wait != 2*time.Second {
+		t.Errorf("Wrong wait time: %s", wait)
+	}
+	resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if wait, ok := retryAfter(resp); !ok || wait <= 50*time.Second || wait > time.Minute {
+		t.Errorf("Wrong wait time for date: %s", wait)
+	}
+	resp.StatusCode = http.StatusBadGateway
+	if _, ok := retryAfter(resp); ok {
+		t.Error("Retry-After should be used only with 429 and 503")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	rt := &RetryTransport{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		wait := rt.backoff(attempt)
+		if wait <= 0 || wait > time.Second {
+			t.Errorf("Wrong backoff for attempt %d: %s", attempt, wait)
+		}
+	}
+}
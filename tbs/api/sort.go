@@ -0,0 +1,216 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/3Blades/cli-tools/tbs/utils"
+)
+
+// ListRenderer returns page renderer of a list command. It checks --order and
+// --sort-by against json fields of model, then filters and sorts results.
+func ListRenderer(formatName string, model interface{}, lf *utils.ListFlags, filters *Filter) (PageRenderer, error) {
+	if err := checkOrder(lf.Order, model); err != nil {
+		return nil, err
+	}
+	keys, err := parseSortKeys(lf.SortBy, model)
+	if err != nil {
+		return nil, err
+	}
+	out := RenderPages(formatName)
+	if len(keys) > 0 {
+		out = &sortPageRenderer{next: out, keys: keys}
+	}
+	return FilterPages(out, filters), nil
+}
+
+// sortKey is a field path of --sort-by, keys prefixed with - sort descending.
+type sortKey struct {
+	path string
+	desc bool
+}
+
+func parseSortKeys(sortBy string, model interface{}) ([]sortKey, error) {
+	var keys []sortKey
+	for _, field := range strings.Split(sortBy, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		key := sortKey{path: field}
+		if strings.HasPrefix(key.path, "-") {
+			key.path, key.desc = key.path[1:], true
+		}
+		key.path = strings.TrimPrefix(key.path, ".")
+		if !hasField(reflect.TypeOf(model), strings.Split(key.path, ".")) {
+			return nil, unknownField(model, field, "--sort-by")
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// checkOrder validates fields of --order sent to the backend.
+func checkOrder(order string, model interface{}) error {
+	for _, field := range strings.Split(order, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		if !hasField(reflect.TypeOf(model), []string{strings.TrimPrefix(field, "-")}) {
+			return unknownField(model, field, "--order")
+		}
+	}
+	return nil
+}
+
+func unknownField(model interface{}, field, flag string) error {
+	return &Error{Kind: KindUsage, Message: fmt.Sprintf("Unknown field '%s' in %s, use one of: %s", field, flag, strings.Join(jsonFields(reflect.TypeOf(model)), ", "))}
+}
+
+// hasField reports whether json field path exists in type t. Maps and
+// interfaces accept any nested path.
+func hasField(t reflect.Type, path []string) bool {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if len(path) == 0 {
+		return true
+	}
+	if t == nil || t.Kind() == reflect.Map || t.Kind() == reflect.Interface {
+		return t != nil
+	}
+	if t.Kind() != reflect.Struct || path[0] == "" {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && hasField(field.Type, path) {
+			return true
+		}
+		if jsonName(field) == path[0] {
+			return hasField(field.Type, path[1:])
+		}
+	}
+	return false
+}
+
+// jsonFields returns sorted top level json field names of type t.
+func jsonFields(t reflect.Type) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var names []string
+	if t == nil || t.Kind() != reflect.Struct {
+		return names
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Anonymous {
+			names = append(names, jsonFields(field.Type)...)
+		} else if name := jsonName(field); name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func jsonName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// sortPageRenderer collects every page and renders results sorted on Close.
+type sortPageRenderer struct {
+	next   PageRenderer
+	keys   []sortKey
+	items  []interface{}
+	values []interface{}
+}
+
+func (s *sortPageRenderer) RenderPage(page interface{}) error {
+	return writeItems(page, func(item interface{}) error {
+		value, err := orderedValue(item)
+		if err != nil {
+			return err
+		}
+		s.items = append(s.items, item)
+		s.values = append(s.values, value)
+		return nil
+	})
+}
+
+func (s *sortPageRenderer) Close() error {
+	order := make([]int, len(s.items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := s.values[order[i]], s.values[order[j]]
+		for _, key := range s.keys {
+			cmp := compareValues(lookup(a, key.path), lookup(b, key.path))
+			if key.desc {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	items := make([]interface{}, len(order))
+	for i, j := range order {
+		items[i] = s.items[j]
+	}
+	if err := s.next.RenderPage(items); err != nil {
+		return err
+	}
+	return s.next.Close()
+}
+
+// compareValues orders missing values first, then numbers, dates and
+// strings by value. Strings are compared case insensitively.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case y:
+				return -1
+			}
+			return 1
+		}
+	}
+	if cmp, ok := filterCompare(a, cellValue(b)); ok {
+		return cmp
+	}
+	x, y := cellValue(a), cellValue(b)
+	// Sizes and counts are sometimes strings, they are sorted as numbers.
+	if m, ok := filterNumber(x); ok {
+		if n, ok := filterNumber(y); ok {
+			return compareFloats(m, n)
+		}
+	}
+	if cmp := strings.Compare(strings.ToLower(x), strings.ToLower(y)); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(x, y)
+}
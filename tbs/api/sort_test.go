@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/3Blades/cli-tools/tbs/utils"
+)
+
+type sortItem struct {
+	Name    string      `json:"name"`
+	Port    int64       `json:"port,omitempty"`
+	Created string      `json:"created,omitempty"`
+	Config  *formatItem `json:"config,omitempty"`
+	Labels  map[string]string
+	ignored string
+}
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := parseSortKeys("name, -config.port,.Labels.team", sortItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []sortKey{{"name", false}, {"config.port", true}, {"Labels.team", false}}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys)
+	}
+	for i := range keys {
+		if keys[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], keys[i])
+		}
+	}
+	for _, sortBy := range []string{"missing", "name.first", "config.missing", "ignored", "config..id"} {
+		if _, err := parseSortKeys(sortBy, &sortItem{}); err == nil {
+			t.Errorf("%s: expected error", sortBy)
+		}
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	if err := checkOrder("name,-created", sortItem{}); err != nil {
+		t.Error(err)
+	}
+	err := checkOrder("-size", sortItem{})
+	if err == nil {
+		t.Fatal("Expected error for unknown field")
+	}
+	if !strings.Contains(err.Error(), "Labels, config, created, name, port") {
+		t.Errorf("Error should list fields: %s", err)
+	}
+	if e, ok := err.(*Error); !ok || e.Kind != KindUsage {
+		t.Errorf("Expected usage error, got %#v", err)
+	}
+	if err := checkOrder("config.port", sortItem{}); err == nil {
+		t.Error("Order should only accept top level fields")
+	}
+}
+
+func TestSortPages(t *testing.T) {
+	items := []*sortItem{
+		{Name: "b", Port: 10, Created: "2018-03-01T10:00:00Z"},
+		{Name: "a", Port: 9, Created: "2018-03-01T09:00:00+02:00"},
+		{Name: "C", Port: 10},
+		{Name: "d", Port: 9, Created: "2018-03-01T09:00:00Z"},
+	}
+	tests := []struct {
+		sortBy   string
+		expected string
+	}{
+		{"name", "a b C d "},
+		{"-name", "d C b a "},
+		{"port,-name", "d a C b "},
+		{"created", "C a d b "},
+		{"config.id", "b a C d "},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		keys, err := parseSortKeys(test.sortBy, sortItem{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &sortPageRenderer{next: NewPageRenderer("{{.Name}} ", &buf), keys: keys}
+		if err = r.RenderPage(items[:2]); err != nil {
+			t.Fatal(err)
+		}
+		if err = r.RenderPage(items[2:]); err != nil {
+			t.Fatal(err)
+		}
+		if err = r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if out := strings.Replace(buf.String(), "\n", "", -1); out != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.sortBy, test.expected, out)
+		}
+	}
+}
+
+func TestListRenderer(t *testing.T) {
+	if _, err := ListRenderer("test_format", sortItem{}, &utils.ListFlags{SortBy: "size"}, nil); err == nil {
+		t.Error("Expected error for unknown sort field")
+	}
+	if _, err := ListRenderer("test_format", sortItem{}, &utils.ListFlags{Order: "-port", SortBy: "name"}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b     interface{}
+		expected int
+	}{
+		{nil, "a", -1},
+		{int64(2), int64(10), -1},
+		{"10", "9", 1},
+		{false, true, -1},
+		{"abc", "ABD", -1},
+		{"B", "b", -1},
+		{"2018-01-02", "2017-12-31T23:00:00Z", 1},
+	}
+	for _, test := range tests {
+		if cmp := compareValues(test.a, test.b); cmp != test.expected {
+			t.Errorf("%v, %v: expected %d, got %d", test.a, test.b, test.expected, cmp)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Claims holds the payload of a JWT issued by 3Blades backend.
+type Claims struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Exp      int64  `json:"exp"`
+}
+
+// ParseToken decodes token claims. Signature is not verified, this is up to the backend.
+func ParseToken(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("Token is not a valid JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, err
+	}
+	claims := &Claims{}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// ExpiresAt returns token expiration time. Zero time means token never expires.
+func (c *Claims) ExpiresAt() time.Time {
+	if c.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(c.Exp, 0)
+}
+
+// Expired reports whether token is already expired.
+func (c *Claims) Expired() bool {
+	return c.Exp != 0 && time.Now().After(c.ExpiresAt())
+}
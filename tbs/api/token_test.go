@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func makeToken(payload string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	body := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return fmt.Sprintf("%s.%s.signature", header, body)
+}
+
+func TestParseToken(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	token := makeToken(fmt.Sprintf(`{"user_id":"1","username":"test","email":"test@example.com","exp":%d}`, exp))
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Username != "test" || claims.UserID != "1" || claims.Email != "test@example.com" {
+		t.Errorf("Wrong claims: %+v", claims)
+	}
+	if claims.ExpiresAt().Unix() != exp {
+		t.Error("Wrong expiration time")
+	}
+	if claims.Expired() {
+		t.Error("Token should not be expired")
+	}
+}
+
+func TestParseTokenExpired(t *testing.T) {
+	token := makeToken(fmt.Sprintf(`{"username":"test","exp":%d}`, time.Now().Add(-time.Minute).Unix()))
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claims.Expired() {
+		t.Error("Token should be expired")
+	}
+}
+
+func TestParseTokenInvalid(t *testing.T) {
+	for _, token := range []string{"", "abc", "a.!!!.c", makeToken("not json")} {
+		if _, err := ParseToken(token); err == nil {
+			t.Errorf("Token '%s' should be invalid", token)
+		}
+	}
+}
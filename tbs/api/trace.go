@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/viper"
+)
+
+// Trace levels set with --debug and --trace flags.
+const (
+	TraceOff = iota
+	// TraceDebug logs method, url, status and timing of every request.
+	TraceDebug
+	// TraceFull logs headers and bodies too.
+	TraceFull
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveFields are json fields redacted in traced bodies.
+var sensitiveFields = map[string]bool{
+	"password":   true,
+	"passphrase": true,
+	"token":      true,
+	"number":     true,
+	"cvc":        true,
+	"exp_month":  true,
+	"exp_year":   true,
+}
+
+var (
+	traceOnce sync.Once
+	traceOut  io.Writer
+)
+
+// TraceLevel returns trace level from --debug and --trace flags.
+func TraceLevel() int {
+	if viper.GetBool("trace") {
+		return TraceFull
+	}
+	if viper.GetBool("debug") {
+		return TraceDebug
+	}
+	return TraceOff
+}
+
+// traceWriter returns stderr or a file set with --trace-file.
+func traceWriter() io.Writer {
+	traceOnce.Do(func() {
+		traceOut = os.Stderr
+		path := viper.GetString("trace_file")
+		if path == "" {
+			return
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			jww.ERROR.Printf("Can't open trace file: %s\n", err)
+			return
+		}
+		traceOut = f
+	})
+	return traceOut
+}
+
+// traceTransport logs every request attempt with secrets redacted.
+type traceTransport struct {
+	next  http.RoundTripper
+	out   io.Writer
+	level int
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "> %s %s\n", req.Method, req.URL)
+	if t.level >= TraceFull {
+		writeHeaders(&buf, "> ", req.Header)
+		if err := bufferBody(req); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			data, _ := ioutil.ReadAll(body)
+			writeBody(&buf, "> ", req.Header.Get("Content-Type"), data)
+		}
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		fmt.Fprintf(&buf, "< %s (%s)\n", err, elapsed)
+		t.out.Write(buf.Bytes())
+		return resp, err
+	}
+	fmt.Fprintf(&buf, "< %s (%s)\n", resp.Status, elapsed)
+	if t.level >= TraceFull {
+		writeHeaders(&buf, "< ", resp.Header)
+		data, rerr := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+		if rerr != nil {
+			fmt.Fprintf(&buf, "< error reading body: %s\n", rerr)
+		}
+		writeBody(&buf, "< ", resp.Header.Get("Content-Type"), data)
+	}
+	t.out.Write(buf.Bytes())
+	return resp, nil
+}
+
+// traceWebsocket logs websocket handshake.
+func traceWebsocket(url string, header http.Header, resp *http.Response, err error, elapsed time.Duration) {
+	level := TraceLevel()
+	if level == TraceOff {
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "> GET %s (websocket)\n", url)
+	if level >= TraceFull {
+		writeHeaders(&buf, "> ", header)
+	}
+	switch {
+	case resp != nil:
+		fmt.Fprintf(&buf, "< %s (%s)\n", resp.Status, elapsed)
+		if level >= TraceFull {
+			writeHeaders(&buf, "< ", resp.Header)
+		}
+	case err != nil:
+		fmt.Fprintf(&buf, "< %s (%s)\n", err, elapsed)
+	}
+	traceWriter().Write(buf.Bytes())
+}
+
+func writeHeaders(w io.Writer, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := strings.Join(header[k], ", ")
+		for _, sensitive := range sensitiveHeaders {
+			if strings.EqualFold(k, sensitive) {
+				value = redacted
+			}
+		}
+		fmt.Fprintf(w, "%s%s: %s\n", prefix, k, value)
+	}
+}
+
+func writeBody(w io.Writer, prefix, contentType string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	var body string
+	switch {
+	case strings.Contains(contentType, "json"):
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			body = fmt.Sprintf("[%d bytes of invalid json]", len(data))
+			break
+		}
+		out, _ := json.Marshal(redact(v))
+		body = string(out)
+	case strings.HasPrefix(contentType, "text/"):
+		body = string(data)
+	default:
+		body = fmt.Sprintf("[%d bytes of %s]", len(data), contentType)
+	}
+	fmt.Fprintf(w, "%s%s\n", prefix, body)
+}
+
+// redact replaces values of sensitive fields in decoded json.
+func redact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if sensitiveFields[strings.ToLower(k)] {
+				val[k] = redacted
+			} else {
+				val[k] = redact(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redact(item)
+		}
+	}
+	return v
+}
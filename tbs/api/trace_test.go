@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceTransportRedactsSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token":"jwt-token","username":"test"}`))
+	}))
+	defer server.Close()
+	var out bytes.Buffer
+	client := &http.Client{Transport: &traceTransport{next: http.DefaultTransport, out: &out, level: TraceFull}}
+	body := `{"username":"test","password":"secret","card":{"number":"4242424242424242","cvc":"123"}}`
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer jwt-token")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	if !strings.Contains(string(respBody), "jwt-token") {
+		t.Error("Response body should be untouched")
+	}
+	trace := out.String()
+	for _, secret := range []string{"secret", "jwt-token", "4242424242424242", "123"} {
+		if strings.Contains(trace, secret) {
+			t.Errorf("Trace contains secret '%s':\n%s", secret, trace)
+		}
+	}
+	for _, expected := range []string{"> POST " + server.URL, "< 200 OK", "Authorization: " + redacted, `"username":"test"`} {
+		if !strings.Contains(trace, expected) {
+			t.Errorf("Trace should contain '%s':\n%s", expected, trace)
+		}
+	}
+}
+
+func TestTraceTransportDebugLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+	var out bytes.Buffer
+	client := &http.Client{Transport: &traceTransport{next: http.DefaultTransport, out: &out, level: TraceDebug}}
+	if _, err := client.Get(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	trace := out.String()
+	if !strings.Contains(trace, "> GET "+server.URL) || !strings.Contains(trace, "< 200 OK") {
+		t.Errorf("Wrong trace:\n%s", trace)
+	}
+	if strings.Contains(trace, "body") || strings.Contains(trace, "Content-Type") {
+		t.Errorf("Debug level should not log headers and bodies:\n%s", trace)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/viper"
+)
+
+// tlsConfig builds TLS settings from ca_file, client_cert, client_key and
+// insecure_skip_verify config values.
+func tlsConfig() (*tls.Config, error) {
+	conf := &tls.Config{
+		InsecureSkipVerify: viper.GetBool("insecure_skip_verify"),
+	}
+	if caFile := viper.GetString("ca_file"); caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("There are no certificates in CA file: %s", caFile)
+		}
+		conf.RootCAs = pool
+	}
+	certFile, keyFile := viper.GetString("client_cert"), viper.GetString("client_key")
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("Both client_cert and client_key are required for client certificate authentication")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// proxyFunc returns proxy set with proxy config value, or one from
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	proxy := viper.GetString("proxy")
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+func mustTLSConfig() *tls.Config {
+	conf, err := tlsConfig()
+	if err != nil {
+		jww.FATAL.Fatal(err)
+	}
+	return conf
+}
+
+func mustProxyFunc() func(*http.Request) (*url.URL, error) {
+	proxy, err := proxyFunc()
+	if err != nil {
+		jww.FATAL.Fatal(err)
+	}
+	return proxy
+}
+
+// URL returns absolute url for api endpoint keeping base path of api root.
+func URL(endpoint string) string {
+	return strings.TrimRight(viper.GetString("root"), "/") + "/" + strings.TrimLeft(endpoint, "/")
+}
+
+// WebsocketURL resolves websocket url returned by api against api root.
+func WebsocketURL(rawurl string) (string, error) {
+	ws, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	if ws.Host == "" {
+		root, err := url.Parse(URL(ws.Path))
+		if err != nil {
+			return "", err
+		}
+		root.RawQuery = ws.RawQuery
+		ws = root
+	}
+	switch ws.Scheme {
+	case "http":
+		ws.Scheme = "ws"
+	case "https":
+		ws.Scheme = "wss"
+	}
+	return ws.String(), nil
+}
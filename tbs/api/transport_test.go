@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/3Blades/go-sdk/models"
+	"github.com/spf13/viper"
+)
+
+func TestClientBasePath(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		name := "Test"
+		json.NewEncoder(w).Encode([]*models.Project{{ID: "1", Name: &name}})
+	}))
+	defer server.Close()
+	defer viper.Reset()
+	viper.Set("root", server.URL+"/3blades/api/")
+	viper.Set("namespace", "test")
+	if _, err := Client().GetProjectIDByName("Test"); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/3blades/api/test/projects/" {
+		t.Errorf("Base path is not honoured: %s", path)
+	}
+}
+
+func TestClientCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer viper.Reset()
+	caFile, err := ioutil.TempFile("", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(caFile.Name())
+	pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caFile.Close()
+
+	if _, err = HTTPClient().Get(server.URL); err == nil {
+		t.Error("Request to server with unknown CA should fail")
+	}
+	viper.Set("ca_file", caFile.Name())
+	resp, err := HTTPClient().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Wrong status code: %d", resp.StatusCode)
+	}
+}
+
+func TestURL(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("root", "https://corp.example/3blades/api/")
+	tests := map[string]string{
+		"/test/projects/": "https://corp.example/3blades/api/test/projects/",
+		"test/projects/":  "https://corp.example/3blades/api/test/projects/",
+	}
+	for endpoint, expected := range tests {
+		if result := URL(endpoint); result != expected {
+			t.Errorf("Wrong url for '%s': %s", endpoint, result)
+		}
+	}
+}
+
+func TestWebsocketURL(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("root", "https://corp.example/3blades/api")
+	tests := map[string]string{
+		"/test/servers/1/logs/?tail=1":    "wss://corp.example/3blades/api/test/servers/1/logs/?tail=1",
+		"ws://logs.example/servers/1/":    "ws://logs.example/servers/1/",
+		"https://logs.example/servers/1/": "wss://logs.example/servers/1/",
+	}
+	for logsURL, expected := range tests {
+		result, err := WebsocketURL(logsURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != expected {
+			t.Errorf("Wrong websocket url for '%s': %s", logsURL, result)
+		}
+	}
+}
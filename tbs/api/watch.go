@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// DefaultWatchInterval is how often watched commands are run.
+const DefaultWatchInterval = 2 * time.Second
+
+// Watch runs a list or describe command repeatedly. On a terminal the output
+// is redrawn in place, otherwise and with ndjson format only new and changed
+// results are printed.
+type Watch struct {
+	Interval time.Duration
+	// Until stops watching when every result matches it.
+	Until *Filter
+	Out   io.Writer
+	// Redraw redraws the whole output, it's set when Out is a terminal.
+	Redraw bool
+
+	seen   map[string]string
+	output []byte
+}
+
+// NewWatch returns watch printing to stdout.
+func NewWatch(interval time.Duration, until *Filter) *Watch {
+	return &Watch{
+		Interval: interval,
+		Until:    until,
+		Out:      os.Stdout,
+		Redraw:   terminal.IsTerminal(int(os.Stdout.Fd())),
+	}
+}
+
+// Run calls run until the root context is cancelled, run fails or Until
+// condition is met. Results run renders are collected and printed by Watch.
+func (w *Watch) Run(run func() error) error {
+	if w.Interval <= 0 {
+		return &Error{Kind: KindUsage, Message: "Watch interval should be positive"}
+	}
+	for {
+		c := &capture{}
+		capturing = c
+		err := run()
+		capturing = nil
+		if rootContext.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = w.print(c); err != nil {
+			return err
+		}
+		done, err := c.matches(w.Until)
+		if done || err != nil {
+			return err
+		}
+		select {
+		case <-rootContext.Done():
+			return nil
+		case <-time.After(w.Interval):
+		}
+	}
+}
+
+func (w *Watch) print(c *capture) error {
+	if c.formatName == "" {
+		return nil
+	}
+	format, err := formatFor(c.formatName)
+	if err != nil {
+		return err
+	}
+	if !w.Redraw || formatKind(format) == "ndjson" {
+		return w.printChanges(c)
+	}
+	var buf bytes.Buffer
+	if err = renderTo(&buf, c.formatName, c.target(c.items)); err != nil {
+		return err
+	}
+	if w.output != nil && bytes.Equal(buf.Bytes(), w.output) {
+		return nil
+	}
+	w.output = buf.Bytes()
+	// Move cursor home and clear the screen.
+	_, err = fmt.Fprintf(w.Out, "\x1b[H\x1b[2J%s", w.output)
+	return err
+}
+
+// printChanges prints results that are new or differ from the previous run.
+func (w *Watch) printChanges(c *capture) error {
+	if w.seen == nil {
+		w.seen = make(map[string]string)
+	}
+	var changed []interface{}
+	for i, item := range c.items {
+		value, err := orderedValue(item)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err = encodeOrdered(&buf, value); err != nil {
+			return err
+		}
+		key := fmt.Sprint(i)
+		for _, field := range idFields {
+			if id := lookup(value, field); id != nil {
+				key = cellValue(id)
+				break
+			}
+		}
+		if w.seen[key] != buf.String() {
+			w.seen[key] = buf.String()
+			changed = append(changed, item)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return renderTo(w.Out, c.formatName, c.target(changed))
+}
+
+// capture collects results rendered by a watched command instead of printing them.
+type capture struct {
+	formatName string
+	items      []interface{}
+	list       bool
+}
+
+var capturing *capture
+
+func (c *capture) add(formatName string, target interface{}) error {
+	c.formatName = formatName
+	if reflect.ValueOf(target).Kind() == reflect.Slice {
+		c.list = true
+	}
+	return writeItems(target, func(item interface{}) error {
+		c.items = append(c.items, item)
+		return nil
+	})
+}
+
+// target returns items as rendered by the command, a single result isn't a list.
+func (c *capture) target(items []interface{}) interface{} {
+	if !c.list && len(items) == 1 {
+		return items[0]
+	}
+	if items == nil {
+		return []interface{}{}
+	}
+	return items
+}
+
+// matches reports whether there are results and all of them match f.
+func (c *capture) matches(f *Filter) (bool, error) {
+	if f == nil || len(f.exprs) == 0 || len(c.items) == 0 {
+		return false, nil
+	}
+	for _, item := range c.items {
+		ok, err := f.Match(item)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// capturePageRenderer passes pages of a watched list command to the capture.
+type capturePageRenderer struct {
+	capture    *capture
+	formatName string
+}
+
+func (c *capturePageRenderer) RenderPage(page interface{}) error {
+	return c.capture.add(c.formatName, page)
+}
+
+func (c *capturePageRenderer) Close() error {
+	c.capture.formatName = c.formatName
+	c.capture.list = true
+	return nil
+}
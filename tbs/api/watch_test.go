@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// watchRuns returns run function rendering the next list of items every call.
+func watchRuns(runs [][]*formatItem) (func() error, *int) {
+	calls := 0
+	return func() error {
+		items := runs[calls]
+		if calls < len(runs)-1 {
+			calls++
+		}
+		out := RenderPages("test_format")
+		if err := out.RenderPage(items); err != nil {
+			return err
+		}
+		return out.Close()
+	}, &calls
+}
+
+func TestWatchUntil(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("output", "ndjson")
+	until := NewFilterVal()
+	until.Set("port=80")
+	var buf bytes.Buffer
+	run, calls := watchRuns([][]*formatItem{
+		{{ID: "1"}, {ID: "2"}},
+		{{ID: "1"}, {ID: "2"}},
+		{{ID: "1", Port: 80}, {ID: "2"}},
+		{{ID: "1", Port: 80}, {ID: "2", Port: 80}},
+	})
+	w := &Watch{Interval: time.Millisecond, Until: until, Out: &buf, Redraw: true}
+	if err := w.Run(run); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected to stop on the last run, got %d calls", *calls)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{`"id":"1","name":"","config":null,"connected":null,"port":0`, `"id":"2"`, `"id":"1","name":"","config":null,"connected":null,"port":80`, `"id":"2","name":"","config":null,"connected":null,"port":80`}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected only changed items, got:\n%s", buf.String())
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, expected[i]) {
+			t.Errorf("Line %d should contain %s, got %s", i, expected[i], line)
+		}
+	}
+	if capturing != nil {
+		t.Error("Capture should be reset after watch")
+	}
+}
+
+func TestWatchRedraw(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("output", "table {{.ID}}")
+	ctx, cancel := context.WithCancel(context.Background())
+	SetRootContext(ctx)
+	defer SetRootContext(context.Background())
+	var buf bytes.Buffer
+	runs := 0
+	run := func() error {
+		runs++
+		if runs == 4 {
+			cancel()
+		}
+		items := []*formatItem{{ID: "1"}}
+		if runs > 2 {
+			items = append(items, &formatItem{ID: "2"})
+		}
+		return Render("test_format", items)
+	}
+	w := &Watch{Interval: time.Millisecond, Out: &buf, Redraw: true}
+	if err := w.Run(run); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\x1b[H\x1b[2JID\n1\n\x1b[H\x1b[2JID\n1\n2\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWatchError(t *testing.T) {
+	w := &Watch{Interval: time.Millisecond, Out: &bytes.Buffer{}}
+	failure := errors.New("failure")
+	if err := w.Run(func() error { return failure }); err != failure {
+		t.Errorf("Expected run error, got %v", err)
+	}
+	w.Interval = 0
+	if err := w.Run(func() error { return nil }); err == nil {
+		t.Error("Expected error for zero interval")
+	}
+}
+
+func TestCaptureTarget(t *testing.T) {
+	c := &capture{}
+	c.add("test_format", &formatItem{ID: "1"})
+	if _, ok := c.target(c.items).(*formatItem); !ok {
+		t.Error("Single result should be rendered as is")
+	}
+	c = &capture{}
+	(&capturePageRenderer{capture: c, formatName: "test_format"}).Close()
+	if items, ok := c.target(c.items).([]interface{}); !ok || len(items) != 0 {
+		t.Error("Empty list should be rendered as a list")
+	}
+}
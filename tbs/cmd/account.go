@@ -2,21 +2,20 @@ package cmd
 
 import (
 	"errors"
-	"fmt"
+	"strings"
 
 	"github.com/3Blades/cli-tools/tbs/api"
+	"github.com/3Blades/cli-tools/tbs/utils"
 	"github.com/3Blades/go-sdk/client/users"
 	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
-	jww "github.com/spf13/jwalterweatherman"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	cmd := accountCmd()
 	cmd.AddCommand(
 		accountCreateCmd(),
-		accountDescribeCmd(),
+		watchable(accountDescribeCmd()),
 		accountUpdateCmd(),
 		accountDeleteCmd(),
 	)
@@ -28,8 +27,7 @@ func accountCmd() *cobra.Command {
 		Use:   "account",
 		Short: "Manage accounts",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "User format")
-	viper.BindPFlag("user_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("user_format", "table {{.ID}}\t{{.Username}}\t{{.Email}}\t{{.FirstName}}\t{{.LastName}}")
 	return cmd
 }
 
@@ -65,6 +63,7 @@ func accountCreateCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := users.NewUsersCreateParams()
+			api.WithContext(params)
 			params.SetUserData(accountBody)
 			resp, err := cli.Users.UsersCreate(params, cli.AuthInfo)
 			if err != nil {
@@ -79,7 +78,7 @@ func accountCreateCmd() *cobra.Command {
 	flags.StringVar(accountBody.Username, "username", "", "New account username (required)")
 	flags.StringVar(accountBody.Password, "password", "", "New account password (required)")
 	flags.StringVar(&accountBody.Profile.URL, "url", "", "New account url")
-	flags.StringVar(&accountBody.Profile.Bio, "bio", "", "New account bio")
+	flags.Var(api.NewTextVal(&accountBody.Profile.Bio), "bio", "New account bio, @file reads it from a file and @- from stdin")
 	flags.StringVar(&accountBody.Profile.Location, "location", "", "New account location")
 	flags.StringVar(&accountBody.Email, "email", "", "New account email (required)")
 	flags.StringVar(&accountBody.Profile.Company, "company", "", "New account company")
@@ -90,28 +89,54 @@ func accountCreateCmd() *cobra.Command {
 func getUserByID(userID string) (*models.User, error) {
 	cli := api.Client()
 	params := users.NewUsersReadParams()
+	api.WithContext(params)
 	params.SetUser(userID)
 	resp, err := cli.Users.UsersRead(params, cli.AuthInfo)
-	return resp.Payload, err
+	if err != nil {
+		return nil, err
+	}
+	return resp.Payload, nil
 }
 
-func getUserByName(username string) (*models.User, error) {
+// userResolver resolves users by username.
+func userResolver() *api.Resolver {
 	cli := api.Client()
-	params := users.NewUsersListParams()
-	params.SetUsername(&username)
-	resp, err := cli.Users.UsersList(params, cli.AuthInfo)
+	return &api.Resolver{
+		Kind: "user",
+		List: func(username string) ([]*api.Resource, error) {
+			params := users.NewUsersListParams()
+			api.WithContext(params)
+			if username != "" {
+				params.SetUsername(&username)
+			}
+			resp, err := cli.Users.UsersList(params, cli.AuthInfo)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]*api.Resource, len(resp.Payload))
+			for i, user := range resp.Payload {
+				out[i] = &api.Resource{ID: user.ID, Value: user}
+				if user.Username != nil {
+					out[i].Name = *user.Username
+				}
+			}
+			return out, nil
+		},
+	}
+}
+
+func getUserByName(username string) (*models.User, error) {
+	res, err := userResolver().Resolve(username)
 	if err != nil {
 		return nil, err
 	}
-	if len(resp.Payload) > 0 {
-		return resp.Payload[0], nil
-	}
-	return nil, fmt.Errorf("There is no user with username: %s", username)
+	return res.Value.(*models.User), nil
 }
 
 func getUserByEmail(email string) (*models.User, error) {
 	cli := api.Client()
 	params := users.NewUsersListParams()
+	api.WithContext(params)
 	params.SetEmail(&email)
 	resp, err := cli.Users.UsersList(params, cli.AuthInfo)
 	if err != nil {
@@ -120,35 +145,38 @@ func getUserByEmail(email string) (*models.User, error) {
 	if len(resp.Payload) > 0 {
 		return resp.Payload[0], nil
 	}
-	return nil, fmt.Errorf("There is no user with email: %s", email)
+	return nil, api.NotFoundError("There is no user with email: %s", email)
+}
+
+// getUser returns user by id, email or username.
+func getUser(user string) (*models.User, error) {
+	switch {
+	case utils.IsUUID(user):
+		return getUserByID(user)
+	case strings.Contains(user, "@"):
+		return getUserByEmail(user)
+	}
+	return getUserByName(user)
 }
 
 func accountDescribeCmd() *cobra.Command {
 	var username, userID string
 	cmd := &cobra.Command{
-		Use:   "describe",
-		Short: "Get information for existing account",
+		Use:   "describe [usernames or ids...]",
+		Short: "Get information for existing accounts",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var user *models.User
-			var err error
-
-			if userID == "" && username == "" {
-				return errors.New("Exactly one of --username and --uuid is required.")
-			}
-
-			if userID != "" {
-				user, err = getUserByID(userID)
-			} else {
-				user, err = getUserByName(username)
-			}
-			if err != nil {
-				return err
-			}
-			return api.Render("user_format", user)
+			return describeEach("user", "user_format", resourceArgs(args, userID, username), func(name string) (interface{}, error) {
+				user, err := getUser(name)
+				if err != nil {
+					return nil, err
+				}
+				return user, nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&username, "username", "", "Username")
 	cmd.Flags().StringVar(&userID, "uuid", "", "User id")
+	deprecateFlags(cmd, "username", "uuid")
 	return cmd
 }
 
@@ -160,13 +188,22 @@ func accountUpdateCmd() *cobra.Command {
 		Profile:  &models.UserProfile{},
 	}
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [username or id]",
 		Short: "Update account",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := singleResource("user", args, userID)
+			if err != nil {
+				return err
+			}
+			user, err := getUser(name)
+			if err != nil {
+				return err
+			}
 			cli := api.Client()
 			params := users.NewUsersUpdateParams()
+			api.WithContext(params)
 			params.SetUserData(accountBody)
-			params.SetUser(userID)
+			params.SetUser(user.ID)
 			resp, err := cli.Users.UsersUpdate(params, cli.AuthInfo)
 			if err != nil {
 				return err
@@ -180,45 +217,42 @@ func accountUpdateCmd() *cobra.Command {
 	cmd.Flags().StringVar(accountBody.Username, "username", "", "Update account username")
 	cmd.Flags().StringVar(accountBody.Password, "password", "", "Update account password")
 	cmd.Flags().StringVar(&accountBody.Profile.URL, "url", "", "Update account url")
-	cmd.Flags().StringVar(&accountBody.Profile.Bio, "bio", "", "Update account bio")
+	cmd.Flags().Var(api.NewTextVal(&accountBody.Profile.Bio), "bio", "Update account bio, @file reads it from a file and @- from stdin")
 	cmd.Flags().StringVar(&accountBody.Profile.Location, "location", "", "Update account location")
 	cmd.Flags().StringVar(&accountBody.Email, "email", "", "Update account email")
 	cmd.Flags().StringVar(&accountBody.Profile.Company, "company", "", "Update account company")
 	cmd.Flags().StringVar(&accountBody.Profile.Timezone, "timezone", "", "Update account timezone")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
 
 func accountDeleteCmd() *cobra.Command {
 	var userID, name, email string
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete user",
+		Use:   "delete [usernames, emails or ids...]",
+		Short: "Delete users",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var user *models.User
-			var err error
 			cli := api.Client()
-			params := users.NewUsersDeleteParams()
-			if name != "" {
-				user, err = getUserByName(name)
-			} else if email != "" {
-				user, err = getUserByEmail(email)
-			} else {
-				user, err = getUserByID(userID)
-			}
-			if err != nil {
-				return err
-			}
-			params.SetUser(user.ID)
-			_, err = cli.Users.UsersDelete(params, cli.AuthInfo)
-			if err != nil {
-				return err
-			}
-			jww.FEEDBACK.Println("User deleted.")
-			return nil
+			return forEachResource("user", resourceArgs(args, name, email, userID), func(name string) error {
+				user, err := getUser(name)
+				if err != nil {
+					return err
+				}
+				params := users.NewUsersDeleteParams()
+				api.WithContext(params)
+				params.SetUser(user.ID)
+				_, err = cli.Users.UsersDelete(params, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				feedback().Printf("User %s deleted.\n", name)
+				return nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&userID, "uuid", "", "User id")
 	cmd.Flags().StringVar(&name, "username", "", "Username")
 	cmd.Flags().StringVar(&email, "email", "", "User email")
+	deprecateFlags(cmd, "uuid", "username", "email")
 	return cmd
 }
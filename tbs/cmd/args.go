@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/3Blades/cli-tools/tbs/api"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// resourceArgs returns resources given as positional arguments. Values of
+// deprecated --name/--uuid style flags are used when there are no arguments.
+func resourceArgs(args []string, flagValues ...string) []string {
+	if len(args) > 0 {
+		return args
+	}
+	for _, value := range flagValues {
+		if value != "" {
+			return []string{value}
+		}
+	}
+	return nil
+}
+
+// singleResource returns the only resource given to commands that can't
+// handle more than one.
+func singleResource(kind string, args []string, flagValues ...string) (string, error) {
+	resources := resourceArgs(args, flagValues...)
+	switch len(resources) {
+	case 0:
+		return "", usageError("You must specify %s name or id", kind)
+	case 1:
+		return resources[0], nil
+	}
+	return "", usageError("You can specify only one %s", kind)
+}
+
+// deprecateFlags marks flags replaced by positional arguments as deprecated.
+func deprecateFlags(cmd *cobra.Command, names ...string) {
+	for _, name := range names {
+		cmd.Flags().MarkDeprecated(name, "pass it as an argument instead")
+	}
+}
+
+func usageError(format string, a ...interface{}) error {
+	return &api.Error{Kind: api.KindUsage, Message: fmt.Sprintf(format, a...)}
+}
+
+// forEachResource runs fn for every resource and reports failures. When there
+// is a single resource its error is returned as is to keep its exit code.
+func forEachResource(kind string, resources []string, fn func(resource string) error) error {
+	if len(resources) == 0 {
+		return usageError("You must specify %s name or id", kind)
+	}
+	if len(resources) == 1 {
+		return fn(resources[0])
+	}
+	var first *api.Error
+	failed := 0
+	for _, resource := range resources {
+		if api.RootContext().Err() != nil {
+			return api.RootContext().Err()
+		}
+		if err := fn(resource); err != nil {
+			apiErr := api.ParseError(err)
+			jww.ERROR.Printf("%s: %s\n", resource, apiErr)
+			if first == nil {
+				first = apiErr
+			}
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &api.Error{
+		Kind:    first.Kind,
+		Status:  first.Status,
+		Message: fmt.Sprintf("Failed for %d of %d %ss", failed, len(resources), kind),
+	}
+}
+
+// describeEach calls get for every resource and renders the results. They are
+// rendered as a list whenever more than one resource was requested, even if
+// only one of them succeeded.
+func describeEach(kind, formatName string, resources []string, get func(resource string) (interface{}, error)) error {
+	var results []interface{}
+	err := forEachResource(kind, resources, func(resource string) error {
+		result, err := get(resource)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+		return nil
+	})
+	if len(results) > 0 {
+		var target interface{} = results
+		if len(resources) == 1 {
+			target = results[0]
+		}
+		if rerr := api.Render(formatName, target); rerr != nil {
+			return rerr
+		}
+	}
+	return err
+}
+
+// quoteAll formats resources for confirmation prompts.
+func quoteAll(resources []string) string {
+	return "'" + strings.Join(resources, "', '") + "'"
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/3Blades/cli-tools/tbs/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd := cacheCmd()
+	cmd.AddCommand(cacheClearCmd())
+	RootCmd.AddCommand(cmd)
+}
+
+func cacheCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache of resource names",
+	}
+}
+
+func cacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove cached resource names",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := api.ClearCache(); err != nil {
+				return err
+			}
+			feedback().Println("Cache cleared.")
+			return nil
+		},
+	}
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/3Blades/cli-tools/tbs/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func init() {
+	cmd := contextCmd()
+	cmd.AddCommand(
+		contextListCmd(),
+		contextUseCmd(),
+		contextAddCmd(),
+		contextRemoveCmd(),
+		contextCurrentCmd(),
+	)
+	RootCmd.AddCommand(cmd)
+}
+
+func contextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage connection contexts",
+	}
+	api.SetTableFormat("context_format", "table {{.Name}}\t{{.Current}}\t{{.Root}}\t{{.Namespace}}\t{{.Project}}")
+	return cmd
+}
+
+type contextInfo struct {
+	Name      string `json:"name"`
+	Current   bool   `json:"current"`
+	Root      string `json:"root"`
+	Namespace string `json:"namespace"`
+	Project   string `json:"project"`
+}
+
+func contextListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List contexts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := readContextConfig()
+			if err != nil {
+				return err
+			}
+			current := api.CurrentContextName()
+			names := make([]string, 0, len(conf.Contexts))
+			for name := range conf.Contexts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			out := make([]*contextInfo, len(names))
+			for i, name := range names {
+				ctx := conf.Contexts[name]
+				out[i] = &contextInfo{
+					Name:      name,
+					Current:   name == current,
+					Root:      ctx.Root,
+					Namespace: ctx.Namespace,
+					Project:   ctx.Project,
+				}
+			}
+			return api.Render("context_format", out)
+		},
+	}
+	return cmd
+}
+
+func contextUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use [name]",
+		Short: "Switch current context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("You must specify context name")
+			}
+			name := strings.ToLower(args[0])
+			conf, err := readContextConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := conf.Contexts[name]; !ok {
+				return fmt.Errorf("There is no context with name: '%s'", name)
+			}
+			conf.CurrentContext = name
+			if err = conf.write(); err != nil {
+				return err
+			}
+			feedback().Printf("Switched to context '%s'\n", name)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func contextAddCmd() *cobra.Command {
+	ctx := &api.Context{}
+	cmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add or update context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("You must specify context name")
+			}
+			name := strings.ToLower(args[0])
+			conf, err := readContextConfig()
+			if err != nil {
+				return err
+			}
+			flags := cmd.Flags()
+			if flags.Changed("token") {
+				if err = saveStoredToken(name, ctx.Token); err != nil {
+					return err
+				}
+				ctx.Token = ""
+			}
+			existing, ok := conf.Contexts[name]
+			if !ok {
+				if ctx.Root == "" {
+					return errors.New("You need to provide api root for a new context")
+				}
+				conf.Contexts[name] = ctx
+			} else {
+				if flags.Changed("root") {
+					existing.Root = ctx.Root
+				}
+				if flags.Changed("namespace") {
+					existing.Namespace = ctx.Namespace
+				}
+				if flags.Changed("project") {
+					existing.Project = ctx.Project
+				}
+				if flags.Changed("token") {
+					existing.Token = ""
+				}
+			}
+			if err = conf.write(); err != nil {
+				return err
+			}
+			if ok {
+				feedback().Printf("Context '%s' updated\n", name)
+			} else {
+				feedback().Printf("Context '%s' added\n", name)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&ctx.Root, "root", "", "API root url")
+	flags.StringVar(&ctx.Namespace, "namespace", "", "Namespace")
+	flags.StringVar(&ctx.Project, "project", "", "Default project name")
+	flags.StringVar(&ctx.Token, "token", "", "API token, saved to the credential store")
+	return cmd
+}
+
+func contextRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm [name]",
+		Short: "Remove context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("You must specify context name")
+			}
+			name := strings.ToLower(args[0])
+			conf, err := readContextConfig()
+			if err != nil {
+				return err
+			}
+			if _, ok := conf.Contexts[name]; !ok {
+				return fmt.Errorf("There is no context with name: '%s'", name)
+			}
+			if err = saveStoredToken(name, ""); err != nil {
+				return err
+			}
+			delete(conf.Contexts, name)
+			if conf.CurrentContext == name {
+				conf.CurrentContext = ""
+			}
+			if err = conf.write(); err != nil {
+				return err
+			}
+			feedback().Printf("Context '%s' removed\n", name)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func contextCurrentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "current",
+		Short: "Print current context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := api.CurrentContextName()
+			if name == "" {
+				return errors.New("Current context is not set")
+			}
+			fmt.Println(name)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// applyContext overrides connection settings with the ones from the selected context.
+// Flags and environment variables still take precedence over context values.
+// Token kept in the config file by older versions is used only when the
+// credential store has no token for the context.
+func applyContext() error {
+	ctx, err := api.CurrentContext()
+	if err != nil || ctx == nil {
+		return err
+	}
+	setFromContext("root", ctx.Root)
+	setFromContext("namespace", ctx.Namespace)
+	setFromContext("project", ctx.Project)
+	viper.Set("token", ctx.Token)
+	return nil
+}
+
+func setFromContext(key, value string) {
+	if value == "" {
+		return
+	}
+	if flag := RootCmd.PersistentFlags().Lookup(key); flag != nil && flag.Changed {
+		return
+	}
+	if _, ok := os.LookupEnv("THREEBLADES_" + strings.ToUpper(key)); ok {
+		return
+	}
+	viper.Set(key, value)
+}
+
+// contextConfig is the part of the config file managed by context commands.
+type contextConfig struct {
+	path           string
+	raw            yaml.MapSlice
+	CurrentContext string                  `yaml:"current-context,omitempty"`
+	Contexts       map[string]*api.Context `yaml:"contexts,omitempty"`
+}
+
+func readContextConfig() (*contextConfig, error) {
+	conf := &contextConfig{
+		path:     configFilePath(),
+		Contexts: make(map[string]*api.Context),
+	}
+	ext := filepath.Ext(conf.path)
+	if ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("Contexts can only be managed in yaml config files: %s", conf.path)
+	}
+	data, err := ioutil.ReadFile(conf.path)
+	if os.IsNotExist(err) {
+		return conf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, &conf.raw); err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, conf); err != nil {
+		return nil, err
+	}
+	contexts := make(map[string]*api.Context, len(conf.Contexts))
+	for name, ctx := range conf.Contexts {
+		if ctx == nil {
+			ctx = &api.Context{}
+		}
+		contexts[strings.ToLower(name)] = ctx
+	}
+	conf.Contexts = contexts
+	conf.CurrentContext = strings.ToLower(conf.CurrentContext)
+	return conf, nil
+}
+
+// write saves contexts back to the config file keeping all other keys intact.
+func (c *contextConfig) write() error {
+	c.set("current-context", c.CurrentContext, c.CurrentContext == "")
+	c.set("contexts", c.Contexts, len(c.Contexts) == 0)
+	data, err := yaml.Marshal(c.raw)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, data, 0600)
+}
+
+func (c *contextConfig) set(key string, value interface{}, remove bool) {
+	for i, item := range c.raw {
+		if item.Key != key {
+			continue
+		}
+		if remove {
+			c.raw = append(c.raw[:i], c.raw[i+1:]...)
+		} else {
+			c.raw[i].Value = value
+		}
+		return
+	}
+	if !remove {
+		c.raw = append(c.raw, yaml.MapItem{Key: key, Value: value})
+	}
+}
+
+func configFilePath() string {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+	return filepath.Join(home, ".threeblades.yaml")
+}
+
+func saveContextToken(name, token string) error {
+	conf, err := readContextConfig()
+	if err != nil {
+		return err
+	}
+	ctx, ok := conf.Contexts[name]
+	if !ok {
+		return fmt.Errorf("There is no context with name: '%s'", name)
+	}
+	ctx.Token = token
+	return conf.write()
+}
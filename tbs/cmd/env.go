@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	jww "github.com/spf13/jwalterweatherman"
 )
 
 func init() {
@@ -35,7 +34,7 @@ func envCmd() *cobra.Command {
 				cmdTmpl += fmt.Sprintf(" --project=%s", projectName)
 			}
 			out += fmt.Sprintf(infoTmpl, cmdTmpl)
-			jww.FEEDBACK.Println(out)
+			fmt.Println(out)
 			return nil
 		},
 	}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -17,12 +16,11 @@ import (
 	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	fCmd := fileCmd()
-	fCmd.AddCommand(fileListCommand())
+	fCmd.AddCommand(watchable(fileListCommand()))
 	fCmd.AddCommand(fileDeleteCmd())
 	fCmd.AddCommand(fileUploadCmd())
 	RootCmd.AddCommand(fCmd)
@@ -33,50 +31,76 @@ func fileCmd() *cobra.Command {
 		Use:   "file",
 		Short: "File management",
 	}
-	// cmd.PersistentFlags().String("format", "json", "Output format")
-	// viper.BindPFlag("file_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("file_format", "table {{.ID}}\t{{.Name}}\t{{.Author}}")
+	api.SetWideFormat("file_format", "table {{.ID}}\t{{.Name}}\t{{.Author}}\t{{.Project}}\t{{.File}}")
 	return cmd
 }
 
 func fileListCommand() *cobra.Command {
 	ls := utils.ListFlags{}
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List files",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := projects.NewProjectsProjectFilesListParams()
-			ls.Apply(params)
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			projectID, err := cli.GetProjectID()
 			if err != nil {
 				return err
 			}
 			params.SetProject(projectID)
-			resp, err := cli.Projects.ProjectsProjectFilesList(params, cli.AuthInfo)
+			filters.PushDown(params)
+			out, err := api.ListRenderer("file_format", models.ProjectFile{}, &ls, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("file_format", resp.Payload)
+			err = ls.Pages(params, func() (int, error) {
+				resp, err := cli.Projects.ProjectsProjectFilesList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	ls.Set(cmd)
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
-func getFileByName(name, projectID string) (*models.ProjectFile, error) {
+// fileResolver resolves project files by name. Files can't be filtered
+// by name on the backend, so all pages are listed.
+func fileResolver(projectID string) *api.Resolver {
 	cli := api.Client()
-	params := projects.NewProjectsProjectFilesListParams()
-	params.SetNamespace(cli.Namespace)
-	params.SetProject(projectID)
-	resp, err := cli.Projects.ProjectsProjectFilesList(params, cli.AuthInfo)
-	if err != nil {
-		return &models.ProjectFile{}, err
-	}
-	if len(resp.Payload) < 1 {
-		return &models.ProjectFile{}, fmt.Errorf("There is no file with name/path: %s", name)
+	return &api.Resolver{
+		Kind: "file",
+		List: func(string) ([]*api.Resource, error) {
+			params := projects.NewProjectsProjectFilesListParams()
+			api.WithContext(params)
+			params.SetNamespace(cli.Namespace)
+			params.SetProject(projectID)
+			var out []*api.Resource
+			lf := &utils.ListFlags{All: true}
+			err := lf.Pages(params, func() (int, error) {
+				resp, err := cli.Projects.ProjectsProjectFilesList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				for _, file := range resp.Payload {
+					out = append(out, &api.Resource{ID: file.ID, Name: file.Name, Value: file})
+				}
+				return len(resp.Payload), nil
+			})
+			return out, err
+		},
 	}
-	return resp.Payload[0], nil
 }
 
 func fileDeleteCmd() *cobra.Command {
@@ -86,31 +110,30 @@ func fileDeleteCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			if len(args) == 0 {
-				return errors.New("You must provide at least one name or id")
+				return usageError("You must provide at least one name or id")
 			}
 			params := projects.NewProjectsProjectFilesDeleteParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			projectID, err := cli.GetProjectID()
 			if err != nil {
 				return err
 			}
 			params.SetProject(projectID)
-			for _, arg := range args {
-				if !utils.IsUUID(arg) {
-					file, err := getFileByName(arg, projectID)
-					if err != nil {
-						jww.FEEDBACK.Printf("There is no file with name %s", arg)
-					}
-					arg = file.ID
+			resolver := fileResolver(projectID)
+			return forEachResource("file", args, func(arg string) error {
+				fileID, err := resolver.ResolveID(arg)
+				if err != nil {
+					return err
 				}
-				params.SetID(arg)
+				params.SetID(fileID)
 				_, err = cli.Projects.ProjectsProjectFilesDelete(params, cli.AuthInfo)
 				if err != nil {
-					jww.FEEDBACK.Println(err)
+					return err
 				}
-				jww.FEEDBACK.Printf("File %s deleted\n", arg)
-			}
-			return nil
+				feedback().Printf("File %s deleted\n", arg)
+				return nil
+			})
 		},
 	}
 	return cmd
@@ -154,34 +177,51 @@ func newFileUploadRequest(uri string, params map[string]string, paramName, path
 	}
 
 	req, err := http.NewRequest("POST", uri, body)
+	if err != nil {
+		return nil, err
+	}
 	if paramName != "" {
 		req.Header.Set("Content-Type", writer.FormDataContentType())
 	} else {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	token := viper.GetString("token")
-	req.Header.Set("AUTHORIZATION", "JWT "+token)
-
-	return req, err
+	api.SetAuthHeader(req.Header)
+	return req, nil
 }
 
 func getFileUploadResponse(request *http.Request) (*bytes.Buffer, error) {
-	client := &http.Client{}
-	resp, err := client.Do(request)
+	ctx, cancel := api.RequestContext()
+	defer cancel()
+	resp, err := api.HTTPClient().Do(request.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+	if err = api.ResponseError(resp); err != nil {
+		return nil, err
+	}
 
 	body := &bytes.Buffer{}
 	_, err = body.ReadFrom(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-
-	resp.Body.Close()
 	return body, nil
 }
 
+// uploadFile sends upload request and decodes the created file.
+func uploadFile(request *http.Request) (*models.ProjectFile, error) {
+	body, err := getFileUploadResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	file := &models.ProjectFile{}
+	if err = json.Unmarshal(body.Bytes(), file); err != nil {
+		return nil, fmt.Errorf("Can't read upload response: %s", err)
+	}
+	return file, nil
+}
+
 func fileUploadCmd() *cobra.Command {
 	uploadBody := &models.ProjectFile{}
 	cmd := &cobra.Command{
@@ -195,45 +235,35 @@ func fileUploadCmd() *cobra.Command {
 				return err
 			}
 
-			rootUrl := viper.GetString("root")
-			endPoint := fmt.Sprintf("/%v/projects/%v/project_files/", cli.Namespace, projectID)
-			apiUrl := rootUrl + endPoint
+			apiUrl := api.URL(fmt.Sprintf("/%v/projects/%v/project_files/", cli.Namespace, projectID))
 
 			extraParams := map[string]string{
 				"project":     projectID,
 				"name":        uploadBody.Name,
 				"base64_data": uploadBody.Content,
 			}
-			if len(args) > 0 {
-				for _, path := range args {
-					request, err := newFileUploadRequest(apiUrl, extraParams, "file", path)
-
-					if err != nil {
-						jww.ERROR.Printf("There was an error uploading file: %s\n", path)
-						continue
-					}
-
-					body, err := getFileUploadResponse(request)
-					if err != nil {
-						jww.ERROR.Printf("There was an error uploading file: %s\n", path)
-					}
-					fmt.Println(body)
-				}
-			} else {
+			if len(args) == 0 {
 				request, err := newFileUploadRequest(apiUrl, extraParams, "", "")
 				if err != nil {
-					jww.ERROR.Printf("There was an error uploading file: %s\n", uploadBody.Name)
+					return err
 				}
-
-				body, err := getFileUploadResponse(request)
-
+				file, err := uploadFile(request)
 				if err != nil {
-					jww.ERROR.Printf("There was an error uploading file: %s\n", uploadBody.Name)
+					return err
 				}
-
-				fmt.Println(body)
+				return api.Render("file_format", file)
 			}
-			return nil
+			return describeEach("file", "file_format", args, func(path string) (interface{}, error) {
+				request, err := newFileUploadRequest(apiUrl, extraParams, "file", path)
+				if err != nil {
+					return nil, err
+				}
+				file, err := uploadFile(request)
+				if err != nil {
+					return nil, err
+				}
+				return file, nil
+			})
 		},
 	}
 	flags := cmd.Flags()
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(filtersHelpCmd())
+}
+
+const filtersHelp = `List commands take --filter with comma separated expressions, results have
+to match all of them. Repeated --filter flags add up.
+
+  FIELD=VALUE          equal, numbers by value and text exactly
+  FIELD!=VALUE         not equal
+  FIELD~REGEXP         matches regular expression
+  FIELD!~REGEXP        doesn't match regular expression
+  FIELD<VALUE          less than a number or a date in text, also <=, > and >=
+  FIELD in (A,B,...)   equal to any of the values
+
+Fields are json field names, nested fields are separated with dots, e.g.
+config.script. A list field matches when any of its items does. Dates are
+given as 2018-01-02 or 2018-01-02T15:04:05Z. Quote values with commas:
+
+  tbs server ls --filter 'status in (Running,Pending),name~^dev-'
+  tbs project ls --filter private=true --filter 'name!="a,b"'
+
+Results are filtered after they are fetched, equality filters on name (and
+private for projects) are also sent to the backend. --limit caps fetched
+results, so fewer may be printed. Use --all to filter every page.
+`
+
+// filtersHelpCmd is a help topic shown with tbs help filters.
+func filtersHelpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "filters",
+		Short: "Filter expressions of list commands",
+		Long:  filtersHelp,
+	}
+}
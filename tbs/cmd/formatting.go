@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/3Blades/cli-tools/tbs/api"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(formattingHelpCmd())
+}
+
+const formattingHelp = `Output of commands is selected with --format (-o):
+
+  json                        indented json (default)
+  yaml                        yaml with the same fields as json
+  ndjson                      a json object per line
+  csv [COLUMNS]               comma separated values, nested fields as dotted columns
+  tsv [COLUMNS]               tab separated values
+  table [TEMPLATE]            aligned columns, default columns without a template
+  wide                        table with more columns
+  custom-columns=H:.path,...  aligned columns selected with json paths
+  custom-columns-file=FILE    headers on the first line of FILE, paths on the second
+  TEMPLATE                    Go template executed for every result
+
+Templates use Go text/template syntax with model fields, e.g.
+
+  tbs server ls -o 'table {{.Name}}\t{{.Status | color "green"}}\t{{humanizeTime .Created}}'
+
+--no-headers leaves out table and csv headers, --query selects part of the output
+with a jq-style or JSONPath expression.
+
+Template functions:
+
+`
+
+// formattingHelpCmd is a help topic shown with tbs help formatting.
+func formattingHelpCmd() *cobra.Command {
+	var buf bytes.Buffer
+	buf.WriteString(formattingHelp)
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	for _, f := range api.TemplateFuncs {
+		fmt.Fprintf(w, "  %s\t%s\n  \t%s\n", f.Usage, f.Doc, f.Example)
+	}
+	w.Flush()
+	return &cobra.Command{
+		Use:   "formatting",
+		Short: "Output formats and template functions",
+		Long:  buf.String(),
+	}
+}
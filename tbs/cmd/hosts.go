@@ -3,21 +3,18 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/3Blades/cli-tools/tbs/utils"
 	"github.com/3Blades/go-sdk/client/hosts"
 	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
-	jww "github.com/spf13/jwalterweatherman"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	cmd := hostsCmd()
 	cmd.AddCommand(
-		hostListCmd(),
+		watchable(hostListCmd()),
 		hostCreateCmd(),
 		hostUpdateCmd(),
 		hostDeleteCmd(),
@@ -30,28 +27,41 @@ func hostsCmd() *cobra.Command {
 		Use:   "host",
 		Short: "Handle your hosts",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "Output format")
-	viper.BindPFlag("host_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("host_format", "table {{.ID}}\t{{.Name}}\t{{.IP}}\t{{.Port}}")
+	api.SetWideFormat("host_format", "table {{.ID}}\t{{.Name}}\t{{.IP}}\t{{.Port}}\t{{.Owner}}")
 	return cmd
 }
 
 func hostListCmd() *cobra.Command {
 	var lf utils.ListFlags
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "Host list",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := hosts.NewHostsListParams()
-			lf.Apply(params)
-			resp, err := cli.Hosts.HostsList(params, cli.AuthInfo)
+			api.WithContext(params)
+			filters.PushDown(params)
+			out, err := api.ListRenderer("host_format", models.DockerHost{}, &lf, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("host_format", resp.Payload)
+			err = lf.Pages(params, func() (int, error) {
+				resp, err := cli.Hosts.HostsList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	lf.Set(cmd)
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
@@ -69,12 +79,14 @@ func hostCreateCmd() *cobra.Command {
 			}
 			cli := api.Client()
 			params := hosts.NewHostsCreateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			params.SetDockerhostData(body)
 			resp, err := cli.Hosts.HostsCreate(params, cli.AuthInfo)
 			if err != nil {
 				return err
 			}
+			api.InvalidateNames("host")
 			return api.Render("host_format", resp.Payload)
 		},
 	}
@@ -91,18 +103,21 @@ func hostUpdateCmd() *cobra.Command {
 		IP:   new(string),
 	}
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [name or id]",
 		Short: "Update host",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var err error
-			if *body.Name == "" && hostID == "" {
-				return errors.New("You must provide either host name or id")
+			// Without an argument --name selects the host, as it did before.
+			host, err := singleResource("host", args, hostID, *body.Name)
+			if err != nil {
+				return err
 			}
 			cli := api.Client()
-			if hostID == "" {
-				hostID, err = cli.GetHostIDByName(*body.Name)
+			hostID, err = cli.HostResolver().ResolveID(host)
+			if err != nil {
+				return err
 			}
 			params := hosts.NewHostsUpdateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			params.SetHost(hostID)
 			params.SetDockerhostData(body)
@@ -110,6 +125,7 @@ func hostUpdateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			api.InvalidateNames("host")
 			return api.Render("host_format", resp.Payload)
 		},
 	}
@@ -117,45 +133,51 @@ func hostUpdateCmd() *cobra.Command {
 	cmd.Flags().StringVar(body.Name, "name", "", "Host name")
 	cmd.Flags().StringVar(body.IP, "ip", "", "Host ip")
 	cmd.Flags().Int64Var(&body.Port, "port", 0, "Host port")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
 
 func hostDeleteCmd() *cobra.Command {
 	var hostID, hostName string
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete host",
+		Use:   "delete [names or ids...]",
+		Short: "Delete hosts",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if hostName == "" && hostID == "" {
-				return errors.New("You must provide host name or id")
+			names := resourceArgs(args, hostID, hostName)
+			if len(names) == 0 {
+				return usageError("You must specify host name or id")
 			}
-			confirm, err := readStdin(
-				fmt.Sprintf("Are you sure you want to delete host '%s'? (Y/n)", hostName))
+			ok, err := confirm(fmt.Sprintf("Are you sure you want to delete hosts %s? (Y/n): ", quoteAll(names)))
 			if err != nil {
 				return err
 			}
-			confirm = strings.ToLower(confirm)
-			if confirm == "n" || confirm == "no" {
-				jww.FEEDBACK.Println("Aborted")
+			if !ok {
+				feedback().Println("Aborted")
 				return nil
 			}
 			cli := api.Client()
-			if hostID == "" {
-				hostID, err = cli.GetHostIDByName(hostName)
+			resolver := cli.HostResolver()
+			return forEachResource("host", names, func(name string) error {
+				hostID, err := resolver.ResolveID(name)
 				if err != nil {
 					return err
 				}
-			}
-			params := hosts.NewHostsDeleteParams()
-			params.SetNamespace(cli.Namespace)
-			params.SetHost(hostID)
-			_, err = cli.Hosts.HostsDelete(params, cli.AuthInfo)
-			if err != nil {
-				return err
-			}
-			jww.FEEDBACK.Println("Host deleted")
-			return nil
+				params := hosts.NewHostsDeleteParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetHost(hostID)
+				_, err = cli.Hosts.HostsDelete(params, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				api.InvalidateNames("host")
+				feedback().Printf("Host %s deleted\n", name)
+				return nil
+			})
 		},
 	}
+	cmd.Flags().StringVar(&hostName, "name", "", "Host name")
+	cmd.Flags().StringVar(&hostID, "uuid", "", "Host id")
+	deprecateFlags(cmd, "name", "uuid")
 	return cmd
 }
@@ -4,14 +4,14 @@ import (
 	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/3Blades/cli-tools/tbs/utils"
 	"github.com/3Blades/go-sdk/client/billing"
+	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	cmd := invoiceCmd()
-	cmd.AddCommand(invoiceListCmd(),
-		invoiceDescribeCmd())
+	cmd.AddCommand(watchable(invoiceListCmd()),
+		watchable(invoiceDescribeCmd()))
 	RootCmd.AddCommand(cmd)
 }
 
@@ -20,58 +20,67 @@ func invoiceCmd() *cobra.Command {
 		Use:   "invoice",
 		Short: "View Invoices",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "Output format")
-	viper.BindPFlag("invoice_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("invoice_format", "table {{.ID}}\t{{.InvoiceDate}}\t{{.Total}}\t{{.Paid}}\t{{.Closed}}")
+	api.SetWideFormat("invoice_format", "table {{.ID}}\t{{.InvoiceDate}}\t{{.Total}}\t{{.AmountDue}}\t{{.Paid}}\t{{.Closed}}\t{{.Subscription}}")
 	return cmd
 }
 
 func invoiceListCmd() *cobra.Command {
 	var lf utils.ListFlags
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List the 10 most recent invoices",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := billing.NewBillingInvoicesListParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			lf.Apply(params)
-
-			resp, err := cli.Billing.BillingInvoicesList(params, cli.AuthInfo)
-
+			filters.PushDown(params)
+			out, err := api.ListRenderer("invoice_format", models.Invoice{}, &lf, filters)
 			if err != nil {
 				return err
 			}
-
-			return api.Render("invoice_format", resp.Payload)
+			err = lf.Pages(params, func() (int, error) {
+				resp, err := cli.Billing.BillingInvoicesList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 
 	lf.Set(cmd)
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
 func invoiceDescribeCmd() *cobra.Command {
 	var invoiceID string
-
 	cmd := &cobra.Command{
-		Use:   "describe",
-		Short: "Getails for an individual invoice.",
+		Use:   "describe [ids...]",
+		Short: "Details for individual invoices.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := billing.NewBillingInvoicesReadParams()
-			params.SetNamespace(cli.Namespace)
-			params.SetID(invoiceID)
-
-			resp, err := cli.Billing.BillingInvoicesRead(params, cli.AuthInfo)
-
-			if err != nil {
-				return err
-			}
-
-			return api.Render("invoice_format", resp.Payload)
+			return describeEach("invoice", "invoice_format", resourceArgs(args, invoiceID), func(id string) (interface{}, error) {
+				params := billing.NewBillingInvoicesReadParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetID(id)
+				resp, err := cli.Billing.BillingInvoicesRead(params, cli.AuthInfo)
+				if err != nil {
+					return nil, err
+				}
+				return resp.Payload, nil
+			})
 		},
 	}
-
 	cmd.Flags().StringVar(&invoiceID, "uuid", "", "Invoice ID")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
@@ -2,15 +2,19 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/3Blades/cli-tools/tbs/api"
+	"github.com/3Blades/cli-tools/tbs/credentials"
 	"github.com/3Blades/go-sdk/client/auth"
 	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
@@ -21,65 +25,291 @@ import (
 func newLoginCmd() *cobra.Command {
 	var username string
 	var password string
+	var force, passwordStdin bool
 	loginCmd := &cobra.Command{
 		Use:   "login [server]",
 		Short: "Login to 3Blades",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if viper.GetString("token") != "" {
-				return nil
+			if token := api.Token(); token != "" && !force {
+				claims, err := api.ParseToken(token)
+				if err == nil && !claims.Expired() {
+					feedback().Printf("Already logged in as %s\n", claims.Username)
+					return renderSession()
+				}
+				feedback().Println("Saved token is expired or invalid, please login again")
 			}
 			var err error
+			if passwordStdin {
+				if password != "" {
+					return errors.New("--password and --password-stdin are mutually exclusive")
+				}
+				if username == "" {
+					return errors.New("--password-stdin requires --username")
+				}
+				password, err = readPasswordStdin()
+				if err != nil {
+					return err
+				}
+			}
 			if username == "" {
 				username, err = readStdin("Username: ")
 				if err != nil {
-					return err
+					return credentialsPromptError(err)
 				}
 			}
 			if password == "" {
 				password, err = readPassword()
 				if err != nil {
-					return err
+					return credentialsPromptError(err)
 				}
 			}
 			token, err := getToken(username, password)
 			if err != nil {
 				return err
 			}
-			viper.Set("token", token)
+			api.SetToken(token)
 			err = saveToken(token)
 			if err != nil {
 				return err
 			}
-			jww.FEEDBACK.Println("\nLogin successful")
-			return nil
+			feedback().Println("Login successful")
+			return renderSession()
 		},
 	}
 	flags := loginCmd.Flags()
 	flags.StringVarP(&username, "username", "u", "", "Username")
 	flags.StringVarP(&password, "password", "p", "", "Password")
+	flags.BoolVar(&passwordStdin, "password-stdin", false, "Read password from stdin")
+	flags.BoolVar(&force, "force", false, "Login even if a valid token is saved")
 	return loginCmd
 }
 
+func credentialsPromptError(err error) error {
+	if err == errNoTerminal {
+		return fmt.Errorf("%s. Use --username with --password-stdin or set THREEBLADES_TOKEN", err)
+	}
+	return err
+}
+
+func newLogoutCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove saved token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := removeToken(); err != nil {
+				return err
+			}
+			api.SetToken("")
+			feedback().Println("Logout successful")
+			return nil
+		},
+	}
+	return cmd
+}
+
+type whoamiInfo struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Namespace string    `json:"namespace"`
+	Root      string    `json:"root"`
+	Context   string    `json:"context,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// currentSession returns user and backend of the saved token.
+func currentSession() (*whoamiInfo, error) {
+	token := api.Token()
+	if token == "" {
+		return nil, errors.New("You are not logged in. Please use login command.")
+	}
+	claims, err := api.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Expired() {
+		return nil, errors.New("Your token is expired. Please use login command.")
+	}
+	user, err := getUserByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	info := &whoamiInfo{
+		UserID:    user.ID,
+		Username:  claims.Username,
+		Email:     user.Email,
+		Namespace: viper.GetString("namespace"),
+		Root:      viper.GetString("root"),
+		Context:   api.CurrentContextName(),
+		ExpiresAt: claims.ExpiresAt(),
+	}
+	if user.Username != nil {
+		info.Username = *user.Username
+	}
+	return info, nil
+}
+
+// renderSession prints the logged in user after login. Login succeeded
+// already, so failure to fetch the user is only reported.
+func renderSession() error {
+	info, err := currentSession()
+	if err != nil {
+		jww.ERROR.Printf("Can't get user details: %s\n", api.ParseError(err))
+		return nil
+	}
+	return api.Render("whoami_format", info)
+}
+
+func newWhoamiCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show current user",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := currentSession()
+			if err != nil {
+				return err
+			}
+			return api.Render("whoami_format", info)
+		},
+	}
+	api.SetTableFormat("whoami_format", "table {{.Username}}\t{{.Email}}\t{{.Namespace}}\t{{.Root}}\t{{.Context}}\t{{.ExpiresAt}}")
+	api.SetWideFormat("whoami_format", "table {{.UserID}}\t{{.Username}}\t{{.Email}}\t{{.Namespace}}\t{{.Root}}\t{{.Context}}\t{{.ExpiresAt}}")
+	return cmd
+}
+
 func init() {
-	RootCmd.AddCommand(newLoginCmd())
+	RootCmd.AddCommand(newLoginCmd(), newLogoutCmd(), newWhoamiCmd())
+	api.TokenRefresher = reauthenticate
+	api.TokenLoader = loadToken
+}
+
+// reauthenticate asks for credentials again when backend rejects saved token.
+func reauthenticate() (string, error) {
+	feedback().Println("Your token is expired or invalid, please login again")
+	var username string
+	if claims, err := api.ParseToken(api.Token()); err == nil {
+		username = claims.Username
+	}
+	var err error
+	if username == "" {
+		username, err = readStdin("Username: ")
+		if err != nil {
+			return "", credentialsPromptError(err)
+		}
+	}
+	password, err := readPassword()
+	if err != nil {
+		return "", credentialsPromptError(err)
+	}
+	token, err := getToken(username, password)
+	if err != nil {
+		return "", err
+	}
+	api.SetToken(token)
+	return token, saveToken(token)
+}
+
+// errNoTerminal is returned by prompts when stdin is not a terminal.
+var errNoTerminal = errors.New("Can't prompt for input: stdin is not a terminal")
+
+func checkTerminal() error {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return errNoTerminal
+	}
+	return nil
 }
 
 func readStdin(promptMsg string) (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-	jww.FEEDBACK.Printf(promptMsg)
-	out, err := reader.ReadString('\n')
+	if err := checkTerminal(); err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, promptMsg)
+	out, err := readInterruptible(func() (string, error) {
+		return bufio.NewReader(os.Stdin).ReadString('\n')
+	})
 	return strings.TrimSpace(out), err
 }
 
+// readInterruptible runs a blocking prompt read and gives up when the
+// command is interrupted, restoring terminal state changed by the read.
+func readInterruptible(read func() (string, error)) (string, error) {
+	fd := int(os.Stdin.Fd())
+	state, _ := terminal.GetState(fd)
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := read()
+		done <- result{out, err}
+	}()
+	ctx := api.RootContext()
+	select {
+	case r := <-done:
+		return r.out, r.err
+	case <-ctx.Done():
+		if state != nil {
+			terminal.Restore(fd, state)
+		}
+		feedback().Println()
+		return "", ctx.Err()
+	}
+}
+
+// confirm asks user to confirm an action. Prompt is skipped with --yes.
+func confirm(promptMsg string) (bool, error) {
+	if viper.GetBool("yes") {
+		return true, nil
+	}
+	answer, err := readStdin(promptMsg)
+	if err != nil {
+		return false, fmt.Errorf("%s. Use --yes to confirm", err)
+	}
+	answer = strings.ToLower(answer)
+	return answer != "n" && answer != "no", nil
+}
+
 func readPassword() (string, error) {
-	jww.FEEDBACK.Printf("Password: ")
-	bytePassword, err := terminal.ReadPassword(int(syscall.Stdin))
-	return strings.TrimSpace(string(bytePassword)), err
+	return readSecret("Password: ")
+}
+
+func readSecret(promptMsg string) (string, error) {
+	if err := checkTerminal(); err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, promptMsg)
+	password, err := readInterruptible(func() (string, error) {
+		bytePassword, err := terminal.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(os.Stderr)
+		return string(bytePassword), err
+	})
+	return strings.TrimSpace(password), err
+}
+
+func readPasswordStdin() (string, error) {
+	password, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(password), "\r\n"), nil
+}
+
+func readPassphrase() (string, error) {
+	if passphrase := os.Getenv("THREEBLADES_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	passphrase, err := readSecret("Credential store passphrase: ")
+	if err == errNoTerminal {
+		return "", fmt.Errorf("%s. Set THREEBLADES_PASSPHRASE", err)
+	}
+	return passphrase, err
 }
 
 func getToken(username, password string) (string, error) {
 	cli := api.Client()
 	params := auth.NewAuthJwtTokenAuthParams()
+	api.WithContext(params)
 	params.SetJwtData(&models.JWTData{
 		Username: &username,
 		Password: &password,
@@ -91,10 +321,71 @@ func getToken(username, password string) (string, error) {
 	return resp.Payload.Token, nil
 }
 
-func tokenFilePath() string {
-	return filepath.Join(filepath.Dir(viper.ConfigFileUsed()), ".threeblades.token")
+// tokenStore returns credential store selected with credential_store config key.
+func tokenStore() (credentials.Store, error) {
+	dir := filepath.Dir(configFilePath())
+	return credentials.New(viper.GetString("credential_store"), dir, readPassphrase)
+}
+
+// loadToken reads token for the current context. THREEBLADES_TOKEN environment
+// variable wins over --token-file flag, which wins over credential store and
+// token of the context in the config file. It runs the first time api needs the token.
+func loadToken() error {
+	if token := os.Getenv("THREEBLADES_TOKEN"); token != "" {
+		viper.Set("token", token)
+		return nil
+	}
+	if tokenFile != "" {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return err
+		}
+		viper.Set("token", strings.TrimSpace(string(token)))
+		return nil
+	}
+	store, err := tokenStore()
+	if err != nil {
+		return err
+	}
+	token, err := store.Get(api.CurrentContextName())
+	if err == credentials.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	viper.Set("token", token)
+	return nil
 }
 
 func saveToken(token string) error {
-	return ioutil.WriteFile(tokenFilePath(), []byte(token), 0600)
+	return saveStoredToken(api.CurrentContextName(), token)
+}
+
+// saveStoredToken saves token of a context to the credential store, empty
+// token removes it.
+func saveStoredToken(context, token string) error {
+	store, err := tokenStore()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return store.Delete(context)
+	}
+	return store.Set(context, token)
+}
+
+func removeToken() error {
+	store, err := tokenStore()
+	if err != nil {
+		return err
+	}
+	name := api.CurrentContextName()
+	if err = store.Delete(name); err != nil {
+		return err
+	}
+	if ctx, err := api.CurrentContext(); err == nil && ctx != nil && ctx.Token != "" {
+		return saveContextToken(name, "")
+	}
+	return nil
 }
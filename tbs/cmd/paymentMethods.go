@@ -4,23 +4,20 @@ import (
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 
 	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/3Blades/cli-tools/tbs/utils"
 	"github.com/3Blades/go-sdk/client/billing"
 	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
-	jww "github.com/spf13/jwalterweatherman"
-	"github.com/spf13/viper"
 	"github.com/stripe/stripe-go"
 	"github.com/stripe/stripe-go/token"
 )
 
 func init() {
 	cmd := billingCmd()
-	cmd.AddCommand(billingListCardCmd(),
-		billingDescribeCardCmd(),
+	cmd.AddCommand(watchable(billingListCardCmd()),
+		watchable(billingDescribeCardCmd()),
 		billingUpdateCardCmd(),
 		billingDeleteCardCmd(),
 		billingCreateCardInteractiveCmd())
@@ -32,50 +29,67 @@ func billingCmd() *cobra.Command {
 		Use:   "billing",
 		Short: "Handle Credit Cards",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "Output format")
-	viper.BindPFlag("billing_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("billing_format", "table {{.ID}}\t{{.Brand}}\t{{.Last4}}\t{{.ExpMonth}}\t{{.ExpYear}}\t{{.Name}}")
+	api.SetWideFormat("billing_format", "table {{.ID}}\t{{.Brand}}\t{{.Last4}}\t{{.ExpMonth}}\t{{.ExpYear}}\t{{.Name}}\t{{.AddressCountry}}\t{{.AddressZip}}")
 	return cmd
 }
 
 func billingListCardCmd() *cobra.Command {
 	var lf utils.ListFlags
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List payment methods",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := billing.NewBillingCardsListParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			lf.Apply(params)
-			resp, err := cli.Billing.BillingCardsList(params, cli.AuthInfo)
+			filters.PushDown(params)
+			out, err := api.ListRenderer("billing_format", models.Card{}, &lf, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("billing_format", resp.Payload)
+			err = lf.Pages(params, func() (int, error) {
+				resp, err := cli.Billing.BillingCardsList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	lf.Set(cmd)
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
 func billingDescribeCardCmd() *cobra.Command {
 	var cardID string
 	cmd := &cobra.Command{
-		Use:   "describe",
+		Use:   "describe [ids...]",
 		Short: "Credit Card Details",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := billing.NewBillingCardsReadParams()
-			params.SetNamespace(cli.Namespace)
-			params.SetID(cardID)
-			resp, err := cli.Billing.BillingCardsRead(params, cli.AuthInfo)
-			if err != nil {
-				return err
-			}
-			return api.Render("billing_format", resp.Payload)
+			return describeEach("card", "billing_format", resourceArgs(args, cardID), func(id string) (interface{}, error) {
+				params := billing.NewBillingCardsReadParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetID(id)
+				resp, err := cli.Billing.BillingCardsRead(params, cli.AuthInfo)
+				if err != nil {
+					return nil, err
+				}
+				return resp.Payload, nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&cardID, "uuid", "", "Card id")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
 
@@ -83,12 +97,18 @@ func billingUpdateCardCmd() *cobra.Command {
 	var cardID string
 	updateBody := &models.CardDataPutandPatch{}
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [id]",
 		Short: "Update credit card information.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			cardID, err := singleResource("card", args, cardID)
+			if err != nil {
+				return err
+			}
 			cli := api.Client()
 
 			params := billing.NewBillingCardsUpdateParams()
+
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			params.SetID(cardID)
 			params.SetCardData(updateBody)
@@ -98,7 +118,7 @@ func billingUpdateCardCmd() *cobra.Command {
 				return err
 			}
 
-			jww.FEEDBACK.Println("Card Updated.")
+			feedback().Println("Card Updated.")
 			return api.Render("billing_format", resp.Payload)
 
 		},
@@ -111,40 +131,47 @@ func billingUpdateCardCmd() *cobra.Command {
 	cmd.Flags().StringVar(&updateBody.AddressState, "state", "", "State")
 	cmd.Flags().StringVar(&updateBody.AddressCountry, "country", "", "Country")
 	cmd.Flags().StringVar(&updateBody.AddressZip, "zip_code", "", "ZIP Code")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
 
 func billingDeleteCardCmd() *cobra.Command {
 	var cardID string
 	cmd := &cobra.Command{
-		Use:   "rm",
-		Short: "Delete a credit card.",
+		Use:   "rm [ids...]",
+		Short: "Delete credit cards.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			confirm, err := readStdin(fmt.Sprint("Are you sure you want to delete this card? (Y/n): "))
+			ids := resourceArgs(args, cardID)
+			if len(ids) == 0 {
+				return usageError("You must specify card id")
+			}
+			ok, err := confirm(fmt.Sprintf("Are you sure you want to delete cards %s? (Y/n): ", quoteAll(ids)))
 			if err != nil {
 				return err
 			}
-			confirm = strings.ToLower(confirm)
-			if confirm == "n" || confirm == "no" {
-				jww.FEEDBACK.Println("Aborted")
+			if !ok {
+				feedback().Println("Aborted")
 				return nil
 			}
 
 			cli := api.Client()
-			params := billing.NewBillingCardsDeleteParams()
-			params.SetNamespace(cli.Namespace)
-			params.SetID(cardID)
-
-			_, err = cli.Billing.BillingCardsDelete(params, cli.AuthInfo)
-			if err != nil {
-				return err
-			}
-			jww.FEEDBACK.Println("Card deleted")
-			return nil
+			return forEachResource("card", ids, func(id string) error {
+				params := billing.NewBillingCardsDeleteParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetID(id)
+				_, err := cli.Billing.BillingCardsDelete(params, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				feedback().Printf("Card %s deleted\n", id)
+				return nil
+			})
 		},
 	}
 
 	cmd.Flags().StringVar(&cardID, "uuid", "", "Card UUID")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
 
@@ -166,17 +193,23 @@ func billingCreateCardInteractiveCmd() *cobra.Command {
 			}
 			body.Number = number
 
-			var expMonth int64
-
-			fmt.Println("Expiry Month:")
-			fmt.Scan(&expMonth)
-			body.ExpMonth = expMonth
-
-			var expYear int64
+			expMonth, err := readStdin("Expiry Month: ")
+			if err != nil {
+				return err
+			}
+			body.ExpMonth, err = strconv.ParseInt(expMonth, 10, 64)
+			if err != nil {
+				return fmt.Errorf("Wrong expiry month: %s", expMonth)
+			}
 
-			fmt.Println("Expiry Year:")
-			fmt.Scan(&expYear)
-			body.ExpYear = expYear
+			expYear, err := readStdin("Expiry Year: ")
+			if err != nil {
+				return err
+			}
+			body.ExpYear, err = strconv.ParseInt(expYear, 10, 64)
+			if err != nil {
+				return fmt.Errorf("Wrong expiry year: %s", expYear)
+			}
 
 			Cvc, err := readStdin("CVC: ")
 			if err != nil {
@@ -240,6 +273,7 @@ func billingCreateCardInteractiveCmd() *cobra.Command {
 
 			cli := api.Client()
 			params := billing.NewBillingCardsCreateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			body := &models.CardDataPost{
 				Token: tkn.ID,
@@ -248,7 +282,7 @@ func billingCreateCardInteractiveCmd() *cobra.Command {
 
 			resp, err := cli.Billing.BillingCardsCreate(params, cli.AuthInfo)
 
-			jww.FEEDBACK.Println("Card added")
+			feedback().Println("Card added")
 			return api.Render("billing_format", resp.Payload)
 		},
 	}
@@ -4,14 +4,14 @@ import (
 	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/3Blades/cli-tools/tbs/utils"
 	"github.com/3Blades/go-sdk/client/billing"
+	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	cmd := planCmd()
-	cmd.AddCommand(planListCmd(),
-		planDescribeCmd(),
+	cmd.AddCommand(watchable(planListCmd()),
+		watchable(planDescribeCmd()),
 	)
 	RootCmd.AddCommand(cmd)
 }
@@ -21,53 +21,66 @@ func planCmd() *cobra.Command {
 		Use:   "plan",
 		Short: "View plans, or manage them if you have the proper permissions.",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "Output format")
-	viper.BindPFlag("plan_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("plan_format", "table {{.ID}}\t{{.Name}}\t{{.Amount}}\t{{.Currency}}\t{{.Interval}}")
+	api.SetWideFormat("plan_format", "table {{.ID}}\t{{.Name}}\t{{.Amount}}\t{{.Currency}}\t{{.Interval}}\t{{.IntervalCount}}")
 	return cmd
 }
 
 func planListCmd() *cobra.Command {
 	var lf utils.ListFlags
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List available plans",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := billing.NewBillingPlansListParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			lf.Apply(params)
-			resp, err := cli.Billing.BillingPlansList(params, cli.AuthInfo)
+			filters.PushDown(params)
+			out, err := api.ListRenderer("plan_format", models.Plan{}, &lf, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("plan_format", resp.Payload)
+			err = lf.Pages(params, func() (int, error) {
+				resp, err := cli.Billing.BillingPlansList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	lf.Set(cmd)
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
 func planDescribeCmd() *cobra.Command {
 	var planID string
 	cmd := &cobra.Command{
-		Use:   "describe",
+		Use:   "describe [ids...]",
 		Short: "Plan Details",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := billing.NewBillingPlansReadParams()
-			params.SetNamespace(cli.Namespace)
-			params.SetID(planID)
-
-			resp, err := cli.Billing.BillingPlansRead(params, cli.AuthInfo)
-
-			if err != nil {
-				return err
-			}
-
-			return api.Render("plan_format", resp.Payload)
+			return describeEach("plan", "plan_format", resourceArgs(args, planID), func(id string) (interface{}, error) {
+				params := billing.NewBillingPlansReadParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetID(id)
+				resp, err := cli.Billing.BillingPlansRead(params, cli.AuthInfo)
+				if err != nil {
+					return nil, err
+				}
+				return resp.Payload, nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&planID, "uuid", "", "Plan ID")
-
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
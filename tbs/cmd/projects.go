@@ -3,7 +3,6 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/3Blades/cli-tools/tbs/utils"
@@ -11,13 +10,12 @@ import (
 	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	cmd := projectsCmd()
 	cmd.AddCommand(
-		projectListCmd(),
+		watchable(projectListCmd()),
 		projectCreateCmd(),
 		projectUpdateCmd(),
 		projectDeleteCmd(),
@@ -31,8 +29,8 @@ func projectsCmd() *cobra.Command {
 		Use:   "project",
 		Short: "Handle projects",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "Output format")
-	viper.BindPFlag("project_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("project_format", "table {{.ID}}\t{{.Name}}\t{{.Description}}\t{{.Private}}")
+	api.SetWideFormat("project_format", "table {{.ID}}\t{{.Name}}\t{{.Description}}\t{{.Private}}\t{{.Owner}}\t{{.Collaborators}}")
 	return cmd
 }
 
@@ -45,19 +43,28 @@ func projectListCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := projects.NewProjectsListParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			lf.Apply(params)
-			params.SetName(filters.Get("name"))
-			params.SetPrivate(filters.Get("private"))
-			resp, err := cli.Projects.ProjectsList(params, cli.AuthInfo)
+			filters.PushDown(params)
+			out, err := api.ListRenderer("project_format", models.Project{}, &lf, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("project_format", resp.Payload)
+			err = lf.Pages(params, func() (int, error) {
+				resp, err := cli.Projects.ProjectsList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	lf.Set(cmd)
-	cmd.Flags().Var(filters, "filter", "Filter results (ex. --filter name=test)")
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
@@ -76,22 +83,24 @@ func projectCreateCmd() *cobra.Command {
 			}
 			cli := api.Client()
 			params := projects.NewProjectsCreateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			params.SetProjectData(body)
 			resp, err := cli.Projects.ProjectsCreate(params, cli.AuthInfo)
 			if err != nil {
-				return fmt.Errorf("There was an error creating project: %s\n", err)
+				return err
 			}
+			api.InvalidateNames("project")
 			err = addMembers(resp.Payload.ID, members...)
 			if err != nil {
 				return err
 			}
-			jww.FEEDBACK.Println("Project successfully created")
+			feedback().Println("Project successfully created")
 			return api.Render("project_format", resp.Payload)
 		},
 	}
 	cmd.Flags().StringVar(body.Name, "name", "", "Project name")
-	cmd.Flags().StringVar(&body.Description, "description", "", "Project description")
+	cmd.Flags().Var(api.NewTextVal(&body.Description), "description", "Project description, @file reads it from a file and @- from stdin")
 	cmd.Flags().BoolVar(&body.Private, "privacy", false, "Should this project be private?")
 	cmd.Flags().StringSliceVar(&members, "members", []string{}, "Project members (comma separated)")
 	return cmd
@@ -100,47 +109,50 @@ func projectCreateCmd() *cobra.Command {
 func projectDeleteCmd() *cobra.Command {
 	var projectID, projectName string
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete project",
+		Use:   "delete [names or ids...]",
+		Short: "Delete projects",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if projectName == "" && projectID == "" {
-				return errors.New("You must specify project name or id")
+			names := resourceArgs(args, projectID, projectName)
+			if len(names) == 0 {
+				return usageError("You must specify project name or id")
 			}
-			confirm, err := readStdin(
-				fmt.Sprintf("Are you sure you want to delete project '%s'? (Y/n): ", projectName))
+			ok, err := confirm(fmt.Sprintf("Are you sure you want to delete projects %s? (Y/n): ", quoteAll(names)))
 			if err != nil {
 				return err
 			}
-			confirm = strings.ToLower(confirm)
-			if confirm == "n" || confirm == "no" {
-				jww.FEEDBACK.Println("Aborted")
+			if !ok {
+				feedback().Println("Aborted")
 				return nil
 			}
 			cli := api.Client()
-			if projectID == "" {
-				projectID, err = cli.GetProjectIDByName(projectName)
+			resolver := cli.ProjectResolver()
+			return forEachResource("project", names, func(name string) error {
+				projectID, err := resolver.ResolveID(name)
 				if err != nil {
 					return err
 				}
-			}
-			deleteParams := projects.NewProjectsDeleteParams()
-			deleteParams.SetNamespace(cli.Namespace)
-			deleteParams.SetProject(projectID)
-			_, err = cli.Projects.ProjectsDelete(deleteParams, cli.AuthInfo)
-			if err != nil {
-				return err
-			}
-			jww.FEEDBACK.Println("Project deleted")
-			return nil
+				deleteParams := projects.NewProjectsDeleteParams()
+				api.WithContext(deleteParams)
+				deleteParams.SetNamespace(cli.Namespace)
+				deleteParams.SetProject(projectID)
+				_, err = cli.Projects.ProjectsDelete(deleteParams, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				api.InvalidateNames("project", "server", "trigger")
+				feedback().Printf("Project %s deleted\n", name)
+				return nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&projectName, "name", "", "Project name")
 	cmd.Flags().StringVar(&projectID, "uuid", "", "Project uuid")
+	deprecateFlags(cmd, "name", "uuid")
 	return cmd
 }
 
 func addUserToProjectCmd() *cobra.Command {
-	var projectName, format string
+	var projectName string
 	cmd := &cobra.Command{
 		Use:   "adduser [email]",
 		Short: "Add collaborator to project",
@@ -163,7 +175,6 @@ func addUserToProjectCmd() *cobra.Command {
 			return addMembers(projectID, email)
 		},
 	}
-	cmd.Flags().StringVar(&format, "format", "json", "Collaborator format")
 	cmd.Flags().StringVar(&projectName, "project", "", "Project name")
 	return cmd
 }
@@ -172,6 +183,7 @@ func addMembers(projectID string, members ...string) error {
 	cli := api.Client()
 	for _, member := range members {
 		params := projects.NewProjectsCollaboratorsCreateParams()
+		api.WithContext(params)
 		params.SetNamespace(cli.Namespace)
 		data := &models.CollaboratorData{
 			Owner:  false,
@@ -181,16 +193,10 @@ func addMembers(projectID string, members ...string) error {
 		params.SetCollaboratorData(data)
 		_, err := cli.Projects.ProjectsCollaboratorsCreate(params, cli.AuthInfo)
 		if err != nil {
-			if nerr, ok := err.(*projects.ProjectsCollaboratorsCreateBadRequest); ok {
-				for _, msg := range nerr.Payload.Member {
-					jww.ERROR.Print(msg)
-				}
-			} else {
-				jww.ERROR.Printf("Error adding memeber: %s.", member)
-			}
+			jww.ERROR.Printf("Error adding member %s: %s\n", member, api.ParseError(err))
 			continue
 		}
-		jww.FEEDBACK.Printf("Member added: %s\n", member)
+		feedback().Printf("Member added: %s\n", member)
 	}
 	return nil
 }
@@ -202,22 +208,25 @@ func projectUpdateCmd() *cobra.Command {
 		Name: new(string),
 	}
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [name or id]",
 		Short: "Update project",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			var err error
-			if *updateBody.Name == "" && projectID == "" {
-				return errors.New("You must provide either project name or id")
+			// Without an argument --name selects the project, as it did before.
+			project, err := singleResource("project", args, projectID, *updateBody.Name)
+			if err != nil {
+				return err
 			}
-			if projectID == "" {
-				projectID, err = cli.GetProjectIDByName(*updateBody.Name)
+			projectID, err = cli.ProjectResolver().ResolveID(project)
+			if err != nil {
+				return err
 			}
 			err = addMembers(projectID, members...)
 			if err != nil {
 				return err
 			}
 			params := projects.NewProjectsUpdateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			params.SetProject(projectID)
 			params.SetProjectData(updateBody)
@@ -225,14 +234,16 @@ func projectUpdateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			jww.FEEDBACK.Println("Project updated.")
+			api.InvalidateNames("project")
+			feedback().Println("Project updated.")
 			return api.Render("project_format", resp.Payload)
 		},
 	}
 	cmd.Flags().StringVar(&projectID, "uuid", "", "Project id")
 	cmd.Flags().StringVar(updateBody.Name, "name", "", "Project name")
-	cmd.Flags().StringVar(&updateBody.Description, "description", "", "Project description")
+	cmd.Flags().Var(api.NewTextVal(&updateBody.Description), "description", "Project description, @file reads it from a file and @- from stdin")
 	cmd.Flags().BoolVar(&updateBody.Private, "privacy", false, "Should this project be private?")
 	cmd.Flags().StringSliceVar(&members, "members", []string{}, "Project members")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
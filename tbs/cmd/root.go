@@ -1,35 +1,131 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var cfgFile, tokenFile string
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "tbs",
 	Short: "3Blades CLI",
+	// Errors are printed by Execute with --error-format.
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Flags are valid at this point, so errors don't need usage.
+		cmd.SilenceUsage = true
+		if f := cmd.Flags().Lookup("legacy-format"); f != nil && f.Changed && !cmd.Flags().Changed("format") {
+			viper.Set("output", f.Value.String())
+		}
+		_, err := api.CurrentQuery()
+		return err
+	},
+}
+
+// quietFeedback discards status messages. Prompts are written to stderr,
+// so they are still shown with --quiet.
+var quietFeedback = jww.NewNotepad(jww.LevelCritical, jww.LevelCritical, ioutil.Discard, ioutil.Discard, "", 0).FEEDBACK
+
+// feedback returns logger for status messages, they are muted by --quiet.
+func feedback() *jww.Feedback {
+	if api.Quiet() {
+		return quietFeedback
+	}
+	return jww.FEEDBACK
 }
 
 // Execute adds all child commands to the root command sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// Second signal kills the process if the command doesn't stop.
+		signal.Stop(signals)
+		cancel()
+	}()
+	api.SetRootContext(ctx)
 	if err := RootCmd.Execute(); err != nil {
-		os.Exit(-1)
+		apiErr := api.ParseError(err)
+		if ctx.Err() != nil {
+			apiErr = api.ParseError(ctx.Err())
+		}
+		printError(apiErr)
+		os.Exit(apiErr.ExitCode())
+	}
+}
+
+// printError writes error to stderr in format set with --error-format.
+func printError(err *api.Error) {
+	if viper.GetString("error_format") == "json" {
+		json.NewEncoder(os.Stderr).Encode(struct {
+			Error *api.Error `json:"error"`
+		}{err})
+		return
 	}
+	fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &api.Error{Kind: api.KindUsage, Message: err.Error()}
+	})
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.threeblades.yaml)")
 	RootCmd.PersistentFlags().String("namespace", "", "3Blades namespace")
 	viper.BindPFlag("namespace", RootCmd.PersistentFlags().Lookup("namespace"))
+	RootCmd.PersistentFlags().String("context", "", "Context to use from config file")
+	viper.BindPFlag("context", RootCmd.PersistentFlags().Lookup("context"))
+	RootCmd.PersistentFlags().StringVar(&tokenFile, "token-file", "", "Read API token from file")
+	RootCmd.PersistentFlags().BoolP("yes", "y", false, "Assume yes for confirmation prompts")
+	viper.BindPFlag("yes", RootCmd.PersistentFlags().Lookup("yes"))
+	RootCmd.PersistentFlags().Int("retries", 3, "Number of retries for failed requests")
+	viper.BindPFlag("retries", RootCmd.PersistentFlags().Lookup("retries"))
+	RootCmd.PersistentFlags().Bool("retry-unsafe", false, "Retry non-idempotent requests too")
+	viper.BindPFlag("retry_unsafe", RootCmd.PersistentFlags().Lookup("retry-unsafe"))
+	RootCmd.PersistentFlags().Duration("timeout", 30*time.Second, "Timeout of a single request attempt")
+	viper.BindPFlag("timeout", RootCmd.PersistentFlags().Lookup("timeout"))
+	RootCmd.PersistentFlags().Duration("request-timeout", 0, "Timeout of an api call including retries (0 means no timeout)")
+	viper.BindPFlag("request_timeout", RootCmd.PersistentFlags().Lookup("request-timeout"))
+	RootCmd.PersistentFlags().Bool("debug", false, "Log api requests and responses")
+	viper.BindPFlag("debug", RootCmd.PersistentFlags().Lookup("debug"))
+	RootCmd.PersistentFlags().Bool("trace", false, "Log api requests and responses with headers and bodies")
+	viper.BindPFlag("trace", RootCmd.PersistentFlags().Lookup("trace"))
+	RootCmd.PersistentFlags().String("trace-file", "", "Write debug and trace logs to file instead of stderr")
+	viper.BindPFlag("trace_file", RootCmd.PersistentFlags().Lookup("trace-file"))
+	RootCmd.PersistentFlags().String("error-format", "text", "Error output format (text or json)")
+	viper.BindPFlag("error_format", RootCmd.PersistentFlags().Lookup("error-format"))
+	RootCmd.PersistentFlags().StringP("format", "o", "", "Output format: json, yaml, csv, tsv, ndjson, table, wide, custom-columns=... or a template (see tbs help formatting)")
+	viper.BindPFlag("output", RootCmd.PersistentFlags().Lookup("format"))
+	// -f was the format shorthand of command groups before --format became global.
+	RootCmd.PersistentFlags().StringP("legacy-format", "f", "", "")
+	RootCmd.PersistentFlags().MarkHidden("legacy-format")
+	RootCmd.PersistentFlags().MarkShorthandDeprecated("legacy-format", "use -o instead")
+	RootCmd.PersistentFlags().BoolP("quiet", "q", false, "Print only ids of resources and no messages")
+	viper.BindPFlag("quiet", RootCmd.PersistentFlags().Lookup("quiet"))
+	RootCmd.PersistentFlags().String("query", "", "Print only the part of the output selected with a jq-style or JSONPath expression, e.g. .status")
+	viper.BindPFlag("query", RootCmd.PersistentFlags().Lookup("query"))
+	RootCmd.PersistentFlags().Bool("no-headers", false, "Don't print headers of table output")
+	viper.BindPFlag("no_headers", RootCmd.PersistentFlags().Lookup("no-headers"))
+	RootCmd.PersistentFlags().Duration("cache-ttl", api.DefaultCacheTTL, "How long resource names resolved to ids are cached (0 disables the cache)")
+	viper.BindPFlag("cache_ttl", RootCmd.PersistentFlags().Lookup("cache-ttl"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -45,13 +141,19 @@ func initConfig() {
 	viper.SetEnvPrefix("THREEBLADES")
 	viper.BindEnv("project")
 	viper.BindEnv("namespace")
+	viper.BindEnv("context")
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err != nil {
 		jww.ERROR.Printf("Error reading config file: %s\n", err)
 	}
-	token, err := ioutil.ReadFile(tokenFilePath())
-	if err == nil {
-		viper.Set("token", string(token))
+	viper.SetDefault("cache_file", filepath.Join(filepath.Dir(configFilePath()), ".threeblades.cache.json"))
+	if api.CurrentContextName() != "" {
+		if err := applyContext(); err != nil {
+			if RootCmd.PersistentFlags().Changed("context") {
+				jww.FATAL.Fatal(err)
+			}
+			jww.ERROR.Println(err)
+		}
 	}
 }
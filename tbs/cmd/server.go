@@ -1,38 +1,31 @@
 package cmd
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
-	"net/url"
-	"os"
-	"os/signal"
-	"time"
 
 	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/3Blades/cli-tools/tbs/utils"
 	"github.com/3Blades/go-sdk/client/projects"
 	"github.com/3Blades/go-sdk/models"
-	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
-	jww "github.com/spf13/jwalterweatherman"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	cmd := serverCmd()
 	triggerCmd := serverTriggerCmd()
 	triggerCmd.AddCommand(
-		serverTriggerListCmd(),
-		serverTriggerDescribeCmd(),
+		watchable(serverTriggerListCmd()),
+		watchable(serverTriggerDescribeCmd()),
 		serverTriggerCreateCmd(),
 		serverTriggerUpdateCmd(),
 		serverTriggerDeleteCmd(),
 	)
 	cmd.AddCommand(
-		serverLsCmd(),
+		watchable(serverLsCmd()),
 		serverCreateCmd(),
 		serverUpdateCmd(),
-		serverDescribeCmd(),
+		watchable(serverDescribeCmd()),
 		serverStartCmd(),
 		serverStopCmd(),
 		serverLogsCmd(),
@@ -46,26 +39,34 @@ func serverCmd() *cobra.Command {
 		Use:   "server",
 		Short: "User server management",
 	}
-	cmd.Flags().String("format", "json", "Output format")
-	viper.BindPFlag("server_format", cmd.Flags().Lookup("format"))
+	api.SetTableFormat("server_format", "table {{.ID}}\t{{.Name}}\t{{.ImageName}}\t{{.ServerSize}}\t{{.Status}}")
+	api.SetWideFormat("server_format", "table {{.ID}}\t{{.Name}}\t{{.ImageName}}\t{{.ServerSize}}\t{{.Status}}\t{{.Host}}\t{{.EndpointURL}}\t{{.Created}}")
 	return cmd
 }
 
 func serverLsCmd() *cobra.Command {
 	ls := &utils.ListFlags{}
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List servers",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			resp, err := cli.ListServers(ls)
+			out, err := api.ListRenderer("server_format", models.Server{}, ls, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("server_format", resp)
+			err = cli.ListServerPages(ls, filters, func(page []*models.Server) error {
+				return out.RenderPage(page)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	ls.Set(cmd)
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
@@ -81,6 +82,7 @@ func serverCreateCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := projects.NewProjectsServersCreateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			body.Config = bodyConf
 			params.SetServerData(body)
@@ -93,13 +95,14 @@ func serverCreateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			api.InvalidateNames("server")
 			return api.Render("server_format", resp.Payload)
 		},
 	}
 	cmd.Flags().StringVar(body.Name, "name", "", "Server name")
 	cmd.Flags().StringVar(&body.ImageName, "image", "", "Server image")
 	cmd.Flags().StringVar(&body.ServerSize, "resources", "", "Server resources")
-	cmd.Flags().StringVar(&body.StartupScript, "startup-script", "", "Server startup script")
+	cmd.Flags().Var(api.NewTextVal(&body.StartupScript), "startup-script", "Server startup script, @file reads it from a file and @- from stdin")
 	cmd.Flags().StringVar(&bodyConf.Function, "function", "", "Function to run")
 	cmd.Flags().StringVar(&bodyConf.Script, "script", "", "Script to run")
 	cmd.Flags().StringVar(&bodyConf.Command, "command", "", "Command to run")
@@ -111,28 +114,22 @@ func serverCreateCmd() *cobra.Command {
 func serverDescribeCmd() *cobra.Command {
 	var name, serverID string
 	cmd := &cobra.Command{
-		Use:   "describe",
+		Use:   "describe [names or ids...]",
 		Short: "Server details",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var server *models.Server
-			var err error
 			cli := api.Client()
-			if name == "" && serverID == "" {
-				return errors.New("You must specify either name or id")
-			}
-			if serverID != "" {
-				server, err = cli.GetServerByID(serverID)
-			} else {
-				server, err = cli.GetServerByName(name)
-			}
-			if err != nil {
-				return err
-			}
-			return api.Render("server_format", server)
+			return describeEach("server", "server_format", resourceArgs(args, serverID, name), func(name string) (interface{}, error) {
+				server, err := getServer(cli, name)
+				if err != nil {
+					return nil, err
+				}
+				return server, nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&name, "name", "", "Server name")
 	cmd.Flags().StringVar(&serverID, "uuid", "", "Server id")
+	deprecateFlags(cmd, "name", "uuid")
 	return cmd
 }
 
@@ -144,14 +141,19 @@ func serverUpdateCmd() *cobra.Command {
 	}
 	bodyConf := &models.ServerConfig{}
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [name or id]",
 		Short: "Update server",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := singleResource("server", args, serverID, name)
+			if err != nil {
+				return err
+			}
 			body.Config = bodyConf
 			cli := api.Client()
 			params := projects.NewProjectsServersUpdateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			err := setServerPathParams(params, serverID, name)
+			err = setServerPathParams(cli, params, server)
 			if err != nil {
 				return err
 			}
@@ -160,6 +162,7 @@ func serverUpdateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			api.InvalidateNames("server")
 			return api.Render("server_format", resp.Payload)
 		},
 	}
@@ -167,98 +170,96 @@ func serverUpdateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&serverID, "server-id", "", "Server id")
 	cmd.Flags().StringVar(body.Name, "name", "", "Server name")
 	cmd.Flags().StringVar(&body.ImageName, "image", "", "Server image")
-	cmd.Flags().StringVar(&body.StartupScript, "resources", "", "Server resources")
-	cmd.Flags().StringVar(&body.StartupScript, "startup-script", "", "Server startup script")
+	cmd.Flags().StringVar(&body.ServerSize, "resources", "", "Server resources")
+	cmd.Flags().Var(api.NewTextVal(&body.StartupScript), "startup-script", "Server startup script, @file reads it from a file and @- from stdin")
 	cmd.Flags().StringVar(&bodyConf.Function, "function", "", "Function to run")
 	cmd.Flags().StringVar(&bodyConf.Script, "script", "", "Script to run")
 	cmd.Flags().StringVar(&bodyConf.Command, "command", "", "Command to run")
 	cmd.Flags().StringVar(&bodyConf.Type, "type", "", "Server type [restful,cron,jupyter]")
+	deprecateFlags(cmd, "server-name", "server-id")
 	return cmd
 }
 
 func serverStartCmd() *cobra.Command {
 	var serverID, name string
 	cmd := &cobra.Command{
-		Use:   "start",
-		Short: "Start server",
+		Use:   "start [names or ids...]",
+		Short: "Start servers",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := projects.NewProjectsServersStartParams()
-			params.SetNamespace(cli.Namespace)
-			err := setServerPathParams(params, serverID, name)
-			if err != nil {
-				return err
-			}
-			_, err = cli.Projects.ProjectsServersStart(params, cli.AuthInfo)
-			if err != nil {
-				return err
-			}
-			jww.FEEDBACK.Println("Server started")
-			return nil
+			return forEachResource("server", resourceArgs(args, serverID, name), func(name string) error {
+				params := projects.NewProjectsServersStartParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				err := setServerPathParams(cli, params, name)
+				if err != nil {
+					return err
+				}
+				_, err = cli.Projects.ProjectsServersStart(params, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				feedback().Printf("Server %s started\n", name)
+				return nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&serverID, "uuid", "", "Server id")
 	cmd.Flags().StringVar(&name, "name", "", "Server name")
+	deprecateFlags(cmd, "name", "uuid")
 	return cmd
 }
 
 func serverStopCmd() *cobra.Command {
 	var name, serverID string
 	cmd := &cobra.Command{
-		Use:   "stop",
-		Short: "Stop server",
+		Use:   "stop [names or ids...]",
+		Short: "Stop servers",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := projects.NewProjectsServersStopParams()
-			params.SetNamespace(cli.Namespace)
-			err := setServerPathParams(params, serverID, name)
-			if err != nil {
-				return err
-			}
-			_, err = cli.Projects.ProjectsServersStop(params, cli.AuthInfo)
-			if err != nil {
-				return err
-			}
-			jww.FEEDBACK.Println("Server stopped")
-			return nil
+			return forEachResource("server", resourceArgs(args, serverID, name), func(name string) error {
+				params := projects.NewProjectsServersStopParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				err := setServerPathParams(cli, params, name)
+				if err != nil {
+					return err
+				}
+				_, err = cli.Projects.ProjectsServersStop(params, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				feedback().Printf("Server %s stopped\n", name)
+				return nil
+			})
 		},
 	}
 	cmd.Flags().StringVar(&name, "name", "", "Server name")
 	cmd.Flags().StringVar(&serverID, "uuid", "", "Server id")
+	deprecateFlags(cmd, "name", "uuid")
 	return cmd
 }
 
 func serverLogsCmd() *cobra.Command {
-	var name, serverID, logsURL string
+	var name, serverID string
 	cmd := &cobra.Command{
-		Use:   "logs",
+		Use:   "logs [name or id]",
 		Short: "Server logs",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if serverID == "" && name == "" {
-				return errors.New("You have to specify server id or name")
-			}
-			cli := api.Client()
-			var server *models.Server
-			var err error
-			if serverID == "" {
-				server, err = cli.GetServerByName(name)
-			} else {
-				server, err = cli.GetServerByID(serverID)
+			name, err := singleResource("server", args, serverID, name)
+			if err != nil {
+				return err
 			}
+			server, err := getServer(api.Client(), name)
 			if err != nil {
 				return err
 			}
-			serverID = server.ID
-			logsURL = server.LogsURL
-			interrupt := make(chan os.Signal, 1)
-			signal.Notify(interrupt, os.Interrupt)
-			ws, err := url.Parse(logsURL)
+			logsURL := server.LogsURL
+			ws, err := api.WebsocketURL(logsURL)
 			if err != nil {
 				return err
 			}
-			header := make(http.Header)
-			header.Add("Origin", viper.GetString("root"))
-			c, _, err := websocket.DefaultDialer.Dial(ws.String(), header)
+			c, err := api.DialWebsocket(ws, make(http.Header))
 			if err != nil {
 				return err
 			}
@@ -273,25 +274,19 @@ func serverLogsCmd() *cobra.Command {
 					if err != nil {
 						return
 					}
-					jww.FEEDBACK.Println(string(message))
+					fmt.Println(string(message))
 				}
 			}()
-			for {
-				select {
-				case <-interrupt:
-					select {
-					case <-done:
-					case <-time.After(time.Second):
-					}
-					c.Close()
-					return nil
-				}
+			select {
+			case <-done:
+			case <-api.RootContext().Done():
 			}
 			return nil
 		},
 	}
 	cmd.Flags().StringVar(&name, "name", "", "Server name")
 	cmd.Flags().StringVar(&serverID, "uuid", "", "Server id")
+	deprecateFlags(cmd, "name", "uuid")
 	return cmd
 }
 
@@ -300,77 +295,87 @@ func serverTriggerCmd() *cobra.Command {
 		Use:   "trigger",
 		Short: "Handle server triggers",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "Output format")
-	viper.BindPFlag("server_trigger_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("server_trigger_format", "table {{.ID}}\t{{.Name}}\t{{.Operation}}")
 	return cmd
 }
 
 func serverTriggerListCmd() *cobra.Command {
-	var name, serverID string
+	var sf serverFlags
 	var lf utils.ListFlags
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List server triggers",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := projects.NewServiceTriggerListParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			err := setTriggerPathParams(params, serverID, name)
+			err := setTriggerPathParams(cli, params, sf.value())
 			if err != nil {
 				return err
 			}
-			lf.Apply(params)
-			resp, err := cli.Projects.ServiceTriggerList(params, cli.AuthInfo)
+			filters.PushDown(params)
+			out, err := api.ListRenderer("server_trigger_format", models.ServerAction{}, &lf, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("server_trigger_format", resp.Payload)
+			err = lf.Pages(params, func() (int, error) {
+				resp, err := cli.Projects.ServiceTriggerList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	lf.Set(cmd)
-	cmd.Flags().StringVar(&name, "server-name", "", "Server name")
-	cmd.Flags().StringVar(&serverID, "server-id", "", "Server id")
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
+	sf.set(cmd)
 	return cmd
 }
 
 func serverTriggerDescribeCmd() *cobra.Command {
-	var serverName, serverID, triggerName, triggerID string
+	var sf serverFlags
+	var triggerName, triggerID string
 	cmd := &cobra.Command{
-		Use:   "describe",
-		Short: "Describe server trigger",
+		Use:   "describe [names or ids...]",
+		Short: "Describe server triggers",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := projects.NewServiceTriggerDeleteParams()
-			params.SetNamespace(cli.Namespace)
-			projectID, serverID, err := getPathIDs(serverID, serverName)
-			if err != nil {
-				return err
-			}
-			if triggerName == "" && triggerID == "" {
-				return errors.New("You have to specify trigger id or name")
-			}
-			var trigger *models.ServerAction
-			if triggerName != "" {
-				trigger, err = cli.GetServerTriggerByName(projectID, serverID, triggerName)
-			} else {
-				trigger, err = cli.GetServerTriggerByID(projectID, serverID, triggerID)
-			}
+			projectID, serverID, err := getPathIDs(cli, sf.value())
 			if err != nil {
 				return err
 			}
-			return api.Render("server_trigger_format", trigger)
+			return describeEach("trigger", "server_trigger_format", resourceArgs(args, triggerID, triggerName), func(name string) (interface{}, error) {
+				var trigger *models.ServerAction
+				var err error
+				if utils.IsUUID(name) {
+					trigger, err = cli.GetServerTriggerByID(projectID, serverID, name)
+				} else {
+					trigger, err = cli.GetServerTriggerByName(projectID, serverID, name)
+				}
+				if err != nil {
+					return nil, err
+				}
+				return trigger, nil
+			})
 		},
 	}
+	sf.set(cmd)
 	flags := cmd.Flags()
-	flags.StringVar(&serverName, "server-name", "", "Server name")
-	flags.StringVar(&serverID, "server-id", "", "Server id")
 	flags.StringVar(&triggerName, "name", "", "Trigger name")
 	flags.StringVar(&triggerID, "id", "", "Trigger id")
+	deprecateFlags(cmd, "name", "id")
 	return cmd
 }
 
 func serverTriggerCreateCmd() *cobra.Command {
-	var name, serverID string
+	var sf serverFlags
 	body := &models.ServerActionData{
 		Webhook: &models.Webhook{
 			URL: new(string),
@@ -383,8 +388,9 @@ func serverTriggerCreateCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := projects.NewServiceTriggerCreateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			err := setTriggerPathParams(params, serverID, name)
+			err := setTriggerPathParams(cli, params, sf.value())
 			if err != nil {
 				return err
 			}
@@ -394,21 +400,21 @@ func serverTriggerCreateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			api.InvalidateNames("trigger")
 			return api.Render("server_trigger_format", resp.Payload)
 		},
 	}
+	sf.set(cmd)
 	flags := cmd.Flags()
-	flags.StringVar(&name, "server-name", "", "Server name")
-	flags.StringVar(&serverID, "server-id", "", "Server id")
 	flags.StringVar(&body.Name, "name", "", "Trigger name")
 	flags.StringVar(&body.Operation, "operation", "", "Server operation [start, terminate]")
 	flags.StringVar(body.Webhook.URL, "webhook-url", "", "Webhook url")
-	flags.VarP(webhookPayload, "webhook-payload", "", "Webhook payload")
+	flags.VarP(webhookPayload, "webhook-payload", "", "Webhook payload as json or yaml, @file reads it from a file and @- from stdin")
 	return cmd
 }
 
 func serverTriggerUpdateCmd() *cobra.Command {
-	var name, serverID string
+	var sf serverFlags
 	body := &models.ServerActionData{
 		Webhook: &models.Webhook{
 			URL: new(string),
@@ -416,80 +422,135 @@ func serverTriggerUpdateCmd() *cobra.Command {
 	}
 	webhookPayload := api.NewJSONVal()
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [name or id]",
 		Short: "Update server trigger",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			trigger, err := singleResource("trigger", args)
+			if err != nil {
+				return err
+			}
 			cli := api.Client()
 			params := projects.NewServiceTriggerUpdateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			err := setTriggerPathParams(params, serverID, name)
+			err = setTriggerPathParams(cli, params, sf.value())
+			if err != nil {
+				return err
+			}
+			triggerID, err := cli.TriggerResolver(params.Project, params.Server).ResolveID(trigger)
 			if err != nil {
 				return err
 			}
+			params.SetTrigger(triggerID)
 			body.Webhook.Payload = webhookPayload.Value
 			params.SetServerAction(body)
 			resp, err := cli.Projects.ServiceTriggerUpdate(params, cli.AuthInfo)
 			if err != nil {
 				return err
 			}
+			api.InvalidateNames("trigger")
 			return api.Render("server_trigger_format", resp.Payload)
 		},
 	}
+	sf.set(cmd)
 	flags := cmd.Flags()
-	flags.StringVar(&name, "server-name", "", "Server name")
-	flags.StringVar(&serverID, "server-id", "", "Server id")
 	flags.StringVar(&body.Name, "name", "", "Trigger name")
 	flags.StringVar(&body.Operation, "operation", "", "Server operation [start, terminate]")
 	flags.StringVar(body.Webhook.URL, "webhook-url", "", "Webhook url")
-	flags.VarP(webhookPayload, "webhook-payload", "", "Webhook payload")
+	flags.VarP(webhookPayload, "webhook-payload", "", "Webhook payload as json or yaml, @file reads it from a file and @- from stdin")
 	return cmd
 }
 
 func serverTriggerDeleteCmd() *cobra.Command {
-	var name, serverID string
+	var sf serverFlags
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete server trigger",
+		Use:   "delete [names or ids...]",
+		Short: "Delete server triggers",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := projects.NewServiceTriggerDeleteParams()
-			params.SetNamespace(cli.Namespace)
-			err := setTriggerPathParams(params, serverID, name)
-			if err != nil {
-				return err
-			}
-			_, err = cli.Projects.ServiceTriggerDelete(params, cli.AuthInfo)
+			projectID, serverID, err := getPathIDs(cli, sf.value())
 			if err != nil {
 				return err
 			}
-			jww.FEEDBACK.Println("Trigger deleted")
-			return nil
+			resolver := cli.TriggerResolver(projectID, serverID)
+			return forEachResource("trigger", args, func(name string) error {
+				triggerID, err := resolver.ResolveID(name)
+				if err != nil {
+					return err
+				}
+				params := projects.NewServiceTriggerDeleteParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetProject(projectID)
+				params.SetServer(serverID)
+				params.SetTrigger(triggerID)
+				_, err = cli.Projects.ServiceTriggerDelete(params, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				api.InvalidateNames("trigger")
+				feedback().Printf("Trigger %s deleted\n", name)
+				return nil
+			})
 		},
 	}
-	flags := cmd.Flags()
-	flags.StringVar(&name, "server-name", "", "Server name")
-	flags.StringVar(&serverID, "server-id", "", "Server id")
+	sf.set(cmd)
 	return cmd
 }
 
-func getPathIDs(serverID, serverName string) (string, string, error) {
-	cli := api.Client()
-	var projectID string
-	if serverID == "" && serverName == "" {
-		return "", "", errors.New("You have to specify server id or name")
-	}
-	if serverID == "" {
-		server, err := cli.GetServerByName(serverName)
-		if err != nil {
-			return "", "", err
+// serverFlags select the server of trigger commands.
+type serverFlags struct {
+	server, name, id string
+}
+
+func (sf *serverFlags) set(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sf.server, "server", "", "Server name or id")
+	cmd.Flags().StringVar(&sf.name, "server-name", "", "Server name")
+	cmd.Flags().StringVar(&sf.id, "server-id", "", "Server id")
+	deprecateFlags(cmd, "server-name", "server-id")
+}
+
+func (sf *serverFlags) value() string {
+	for _, value := range []string{sf.server, sf.id, sf.name} {
+		if value != "" {
+			return value
 		}
-		serverID = server.ID
 	}
-	projectID, err := cli.GetProjectID()
+	return ""
+}
+
+// getServer returns server in the current project by name or id.
+func getServer(cli *api.APIClient, nameOrID string) (*models.Server, error) {
+	r, err := cli.ServerResolver()
+	if err != nil {
+		return nil, err
+	}
+	if utils.IsUUID(nameOrID) {
+		return cli.GetServerByID(nameOrID)
+	}
+	if serverID, ok := r.CachedID(nameOrID); ok {
+		return cli.GetServerByID(serverID)
+	}
+	res, err := r.Resolve(nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	return res.Value.(*models.Server), nil
+}
+
+func getPathIDs(cli *api.APIClient, server string) (string, string, error) {
+	if server == "" {
+		return "", "", usageError("You have to specify server name or id")
+	}
+	r, err := cli.ServerResolver()
+	if err != nil {
+		return "", "", err
+	}
+	serverID, err := r.ResolveID(server)
 	if err != nil {
 		return "", "", err
 	}
-	return projectID, serverID, nil
+	return r.Scope, serverID, nil
 }
 
 type (
@@ -506,8 +567,8 @@ type (
 	}
 )
 
-func setServerPathParams(target ServerPathParamsSetter, serverID, serverName string) error {
-	projectID, serverID, err := getPathIDs(serverID, serverName)
+func setServerPathParams(cli *api.APIClient, target ServerPathParamsSetter, server string) error {
+	projectID, serverID, err := getPathIDs(cli, server)
 	if err != nil {
 		return err
 	}
@@ -516,8 +577,8 @@ func setServerPathParams(target ServerPathParamsSetter, serverID, serverName str
 	return nil
 }
 
-func setTriggerPathParams(target TriggerPathParamsSetter, serverID, serverName string) error {
-	projectID, serverID, err := getPathIDs(serverID, serverName)
+func setTriggerPathParams(cli *api.APIClient, target TriggerPathParamsSetter, server string) error {
+	projectID, serverID, err := getPathIDs(cli, server)
 	if err != nil {
 		return err
 	}
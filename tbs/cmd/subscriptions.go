@@ -2,22 +2,19 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/3Blades/cli-tools/tbs/api"
 	"github.com/3Blades/cli-tools/tbs/utils"
 	"github.com/3Blades/go-sdk/client/billing"
 	"github.com/3Blades/go-sdk/models"
 	"github.com/spf13/cobra"
-	jww "github.com/spf13/jwalterweatherman"
-	"github.com/spf13/viper"
 )
 
 func init() {
 	cmd := subscriptionCmd()
-	cmd.AddCommand(subscriptionListCmd(),
+	cmd.AddCommand(watchable(subscriptionListCmd()),
 		subscriptionCreateCmd(),
-		subscriptionDescribeCmd(),
+		watchable(subscriptionDescribeCmd()),
 		subscriptionDeleteCmd())
 	RootCmd.AddCommand(cmd)
 }
@@ -27,29 +24,41 @@ func subscriptionCmd() *cobra.Command {
 		Use:   "subscription",
 		Short: "Manage your subscriptions",
 	}
-	cmd.PersistentFlags().StringP("format", "f", "json", "Output format")
-	viper.BindPFlag("subscription_format", cmd.PersistentFlags().Lookup("format"))
+	api.SetTableFormat("subscription_format", "table {{.ID}}\t{{.Plan}}\t{{.Status}}\t{{.Created}}")
 	return cmd
 }
 
 func subscriptionListCmd() *cobra.Command {
 	var lf utils.ListFlags
+	filters := api.NewFilterVal()
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List Subscriptions",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := billing.NewBillingSubscriptionsListParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
-			lf.Apply(params)
-			resp, err := cli.Billing.BillingSubscriptionsList(params, cli.AuthInfo)
+			filters.PushDown(params)
+			out, err := api.ListRenderer("subscription_format", models.Subscription{}, &lf, filters)
 			if err != nil {
 				return err
 			}
-			return api.Render("subscription_format", resp.Payload)
+			err = lf.Pages(params, func() (int, error) {
+				resp, err := cli.Billing.BillingSubscriptionsList(params, cli.AuthInfo)
+				if err != nil {
+					return 0, err
+				}
+				return len(resp.Payload), out.RenderPage(resp.Payload)
+			})
+			if err != nil {
+				return err
+			}
+			return out.Close()
 		},
 	}
 	lf.Set(cmd)
+	cmd.Flags().Var(filters, "filter", "Filter results, e.g. --filter 'name~^dev-,created>2018-01-01' (see tbs help filters)")
 	return cmd
 }
 
@@ -63,6 +72,7 @@ func subscriptionCreateCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
 			params := billing.NewBillingSubscriptionsCreateParams()
+			api.WithContext(params)
 			params.SetNamespace(cli.Namespace)
 			params.SetSubscriptionData(body)
 			resp, err := cli.Billing.BillingSubscriptionsCreate(params, cli.AuthInfo)
@@ -70,7 +80,7 @@ func subscriptionCreateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			jww.FEEDBACK.Println("Subscription successfully created")
+			feedback().Println("Subscription successfully created")
 			return api.Render("subscription_format", resp.Payload)
 		},
 	}
@@ -80,62 +90,63 @@ func subscriptionCreateCmd() *cobra.Command {
 
 func subscriptionDescribeCmd() *cobra.Command {
 	var subscriptionID string
-
 	cmd := &cobra.Command{
-		Use:   "describe",
+		Use:   "describe [ids...]",
 		Short: "Subscription Details",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cli := api.Client()
-			params := billing.NewBillingSubscriptionsReadParams()
-			params.SetNamespace(cli.Namespace)
-			params.SetID(subscriptionID)
-
-			resp, err := cli.Billing.BillingSubscriptionsRead(params, cli.AuthInfo)
-
-			if err != nil {
-				return err
-			}
-			return api.Render("subscription_format", resp.Payload)
-
+			return describeEach("subscription", "subscription_format", resourceArgs(args, subscriptionID), func(id string) (interface{}, error) {
+				params := billing.NewBillingSubscriptionsReadParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetID(id)
+				resp, err := cli.Billing.BillingSubscriptionsRead(params, cli.AuthInfo)
+				if err != nil {
+					return nil, err
+				}
+				return resp.Payload, nil
+			})
 		},
 	}
-
 	cmd.Flags().StringVar(&subscriptionID, "uuid", "", "Subscription ID")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
 
 func subscriptionDeleteCmd() *cobra.Command {
 	var subscriptionID string
 	cmd := &cobra.Command{
-		Use:   "cancel",
-		Short: "Cancel a subscription",
+		Use:   "cancel [ids...]",
+		Short: "Cancel subscriptions",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			confirm, err := readStdin(fmt.Sprint("Are you sure you want to cancel this subscription? (Y/n): "))
+			ids := resourceArgs(args, subscriptionID)
+			if len(ids) == 0 {
+				return usageError("You must specify subscription id")
+			}
+			ok, err := confirm(fmt.Sprintf("Are you sure you want to cancel subscriptions %s? (Y/n): ", quoteAll(ids)))
 			if err != nil {
 				return err
 			}
-			confirm = strings.ToLower(confirm)
-			if confirm == "n" || confirm == "no" {
-				jww.FEEDBACK.Println("Aborted")
+			if !ok {
+				feedback().Println("Aborted")
 				return nil
 			}
-
 			cli := api.Client()
-			params := billing.NewBillingSubscriptionsDeleteParams()
-			params.SetNamespace(cli.Namespace)
-			params.SetID(subscriptionID)
-
-			_, err = cli.Billing.BillingSubscriptionsDelete(params, cli.AuthInfo)
-
-			if err != nil {
-				return err
-			}
-
-			jww.FEEDBACK.Println("Subscription canceled.")
-			return nil
+			return forEachResource("subscription", ids, func(id string) error {
+				params := billing.NewBillingSubscriptionsDeleteParams()
+				api.WithContext(params)
+				params.SetNamespace(cli.Namespace)
+				params.SetID(id)
+				_, err := cli.Billing.BillingSubscriptionsDelete(params, cli.AuthInfo)
+				if err != nil {
+					return err
+				}
+				feedback().Printf("Subscription %s canceled.\n", id)
+				return nil
+			})
 		},
 	}
-
 	cmd.Flags().StringVar(&subscriptionID, "uuid", "", "Subscription ID")
+	deprecateFlags(cmd, "uuid")
 	return cmd
 }
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/3Blades/cli-tools/tbs/api"
+	"github.com/spf13/cobra"
+)
+
+// watchable adds --watch, --interval and --until flags to a list or describe command.
+func watchable(cmd *cobra.Command) *cobra.Command {
+	var watch bool
+	until := api.NewFilterVal()
+	interval := api.DefaultWatchInterval
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !watch && !cmd.Flags().Changed("until") {
+			return run(cmd, args)
+		}
+		return api.NewWatch(interval, until).Run(func() error {
+			return run(cmd, args)
+		})
+	}
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Run the command again every --interval until interrupted, only changes are printed when output isn't a terminal")
+	cmd.Flags().DurationVar(&interval, "interval", interval, "How often results are fetched with --watch")
+	cmd.Flags().Var(until, "until", "Watch until every result matches the filter, e.g. --until status=Running (see tbs help filters)")
+	return cmd
+}
@@ -0,0 +1,161 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// EncryptedStore keeps all tokens in a single file encrypted with AES-GCM.
+// Encryption key is derived from passphrase with scrypt.
+type EncryptedStore struct {
+	Dir        string
+	Passphrase PassphraseFunc
+	passphrase string
+}
+
+// Path returns encrypted tokens file path.
+func (s *EncryptedStore) Path() string {
+	return filepath.Join(s.Dir, ".threeblades.tokens")
+}
+
+func (s *EncryptedStore) Get(context string) (string, error) {
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	data, ok := tokens[context]
+	if !ok {
+		return "", ErrNotFound
+	}
+	token, err := s.decrypt(data)
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+func (s *EncryptedStore) Set(context, token string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	data, err := s.encrypt([]byte(token))
+	if err != nil {
+		return err
+	}
+	tokens[context] = data
+	return s.write(tokens)
+}
+
+func (s *EncryptedStore) Delete(context string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[context]; !ok {
+		return nil
+	}
+	delete(tokens, context)
+	return s.write(tokens)
+}
+
+func (s *EncryptedStore) read() (map[string][]byte, error) {
+	tokens := make(map[string][]byte)
+	data, err := ioutil.ReadFile(s.Path())
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &tokens)
+	return tokens, err
+}
+
+func (s *EncryptedStore) write(tokens map[string][]byte) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path(), data, 0600)
+}
+
+func (s *EncryptedStore) key(salt []byte) ([]byte, error) {
+	if s.passphrase == "" {
+		if s.Passphrase == nil {
+			return nil, errors.New("Passphrase is required for encrypted credential store")
+		}
+		passphrase, err := s.Passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			return nil, errors.New("Passphrase can't be blank")
+		}
+		s.passphrase = passphrase
+	}
+	return scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, keySize)
+}
+
+// encrypt returns salt, nonce and sealed token concatenated together.
+func (s *EncryptedStore) encrypt(token []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := append(salt, nonce...)
+	return gcm.Seal(out, nonce, token, nil), nil
+}
+
+func (s *EncryptedStore) decrypt(data []byte) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, errors.New("Encrypted token is corrupted")
+	}
+	gcm, err := s.cipher(data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("Encrypted token is corrupted")
+	}
+	token, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("Unable to decrypt token. Wrong passphrase?")
+	}
+	return token, nil
+}
+
+func (s *EncryptedStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := s.key(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,41 @@
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps every token in a separate plaintext file.
+type FileStore struct {
+	Dir string
+}
+
+// Path returns token file path for context.
+func (s *FileStore) Path(context string) string {
+	if context == "" {
+		return filepath.Join(s.Dir, ".threeblades.token")
+	}
+	return filepath.Join(s.Dir, fmt.Sprintf(".threeblades.%s.token", context))
+}
+
+func (s *FileStore) Get(context string) (string, error) {
+	token, err := ioutil.ReadFile(s.Path(context))
+	if os.IsNotExist(err) {
+		return "", ErrNotFound
+	}
+	return string(token), err
+}
+
+func (s *FileStore) Set(context, token string) error {
+	return ioutil.WriteFile(s.Path(context), []byte(token), 0600)
+}
+
+func (s *FileStore) Delete(context string) error {
+	err := os.Remove(s.Path(context))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
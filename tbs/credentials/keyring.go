@@ -0,0 +1,48 @@
+package credentials
+
+import "github.com/zalando/go-keyring"
+
+const keyringService = "threeblades"
+
+// keyringAvailable returns error when OS keyring can't be used, e.g. there
+// is no Secret Service running. It's replaced in tests.
+var keyringAvailable = func(service string) error {
+	_, err := keyring.Get(service, "token")
+	if err == keyring.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// KeyringStore keeps tokens in OS keyring: Secret Service on Linux,
+// Keychain on macOS and Credential Manager on Windows.
+type KeyringStore struct {
+	Service string
+}
+
+func (s *KeyringStore) user(context string) string {
+	if context == "" {
+		return "token"
+	}
+	return "context:" + context
+}
+
+func (s *KeyringStore) Get(context string) (string, error) {
+	token, err := keyring.Get(s.Service, s.user(context))
+	if err == keyring.ErrNotFound {
+		return "", ErrNotFound
+	}
+	return token, err
+}
+
+func (s *KeyringStore) Set(context, token string) error {
+	return keyring.Set(s.Service, s.user(context), token)
+}
+
+func (s *KeyringStore) Delete(context string) error {
+	err := keyring.Delete(s.Service, s.user(context))
+	if err == keyring.ErrNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+// Package credentials stores API tokens per context.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// ErrNotFound is returned when there is no token saved for a context.
+var ErrNotFound = errors.New("Token not found")
+
+// Store keeps tokens keyed by context name. Empty name is used when no context is selected.
+type Store interface {
+	Get(context string) (string, error)
+	Set(context, token string) error
+	Delete(context string) error
+}
+
+// PassphraseFunc returns passphrase used by encrypted store.
+type PassphraseFunc func() (string, error)
+
+// New returns credential store by its name: file, encrypted or keyring.
+// File based stores keep their data inside dir, file store is also used
+// when OS keyring isn't available.
+func New(name, dir string, passphrase PassphraseFunc) (Store, error) {
+	switch name {
+	case "", "file":
+		return &FileStore{Dir: dir}, nil
+	case "encrypted":
+		return &EncryptedStore{Dir: dir, Passphrase: passphrase}, nil
+	case "keyring":
+		if err := keyringAvailable(keyringService); err != nil {
+			jww.ERROR.Printf("Keyring is not available, using token file instead: %s\n", err)
+			return &FileStore{Dir: dir}, nil
+		}
+		return &KeyringStore{Service: keyringService}, nil
+	default:
+		return nil, fmt.Errorf("Unknown credential store: '%s'. Use one of: file, encrypted, keyring", name)
+	}
+}
@@ -0,0 +1,128 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func testStore(t *testing.T, s Store) {
+	if _, err := s.Get(""); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got: %v", err)
+	}
+	if err := s.Set("", "default-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("staging", "staging-token"); err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "default-token" {
+		t.Errorf("Wrong default token: %s", token)
+	}
+	token, err = s.Get("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "staging-token" {
+		t.Errorf("Wrong context token: %s", token)
+	}
+	if err = s.Delete("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Get("staging"); err != ErrNotFound {
+		t.Errorf("Token should be deleted, got: %v", err)
+	}
+	if err = s.Delete("staging"); err != nil {
+		t.Error("Deleting missing token should not fail")
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	testStore(t, &FileStore{Dir: dir})
+}
+
+func TestEncryptedStore(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	passphrase := func() (string, error) { return "secret", nil }
+	testStore(t, &EncryptedStore{Dir: dir, Passphrase: passphrase})
+}
+
+func TestEncryptedStoreWrongPassphrase(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	s := &EncryptedStore{Dir: dir, Passphrase: func() (string, error) { return "secret", nil }}
+	if err := s.Set("", "token"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) == "token" {
+		t.Error("Token is not encrypted")
+	}
+	s = &EncryptedStore{Dir: dir, Passphrase: func() (string, error) { return "wrong", nil }}
+	if _, err = s.Get(""); err == nil {
+		t.Error("Wrong passphrase should fail")
+	}
+}
+
+func withKeyring(err error) func() {
+	available := keyringAvailable
+	keyringAvailable = func(string) error { return err }
+	return func() {
+		keyringAvailable = available
+	}
+}
+
+func TestNew(t *testing.T) {
+	defer withKeyring(nil)()
+	for name, expected := range map[string]string{
+		"":          "*credentials.FileStore",
+		"file":      "*credentials.FileStore",
+		"encrypted": "*credentials.EncryptedStore",
+		"keyring":   "*credentials.KeyringStore",
+	} {
+		s, err := New(name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprintf("%T", s) != expected {
+			t.Errorf("Wrong store for '%s': %T", name, s)
+		}
+	}
+	if _, err := New("unknown", "", nil); err == nil {
+		t.Error("Unknown store should return an error")
+	}
+}
+
+func TestNewKeyringUnavailable(t *testing.T) {
+	defer withKeyring(errors.New("The name org.freedesktop.secrets was not provided"))()
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	s, err := New("keyring", dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*FileStore); !ok {
+		t.Fatalf("Expected file store fallback, got %T", s)
+	}
+	testStore(t, s)
+}
@@ -4,9 +4,13 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// DefaultPageSize is the number of results fetched per request with --all.
+const DefaultPageSize = 100
+
 type ListFlagsSetter interface {
 	SetLimit(*string)
 	SetOffset(*string)
@@ -16,6 +20,11 @@ type ListFlagsSetter interface {
 type ListFlags struct {
 	Limit, Offset int
 	Order         string
+	SortBy        string
+	All           bool
+	PageSize      int
+
+	limitFlag *pflag.Flag
 }
 
 func (lf *ListFlags) Apply(l ListFlagsSetter) {
@@ -26,9 +35,55 @@ func (lf *ListFlags) Apply(l ListFlagsSetter) {
 	l.SetOrdering(&lf.Order)
 }
 
+// Pages calls fetch for every page of results. fetch returns number of
+// results on the page. Without --all only a single page is fetched.
+// With --all pages are fetched until the backend returns a short page,
+// --limit caps the total number of results. Default limit from the config
+// file doesn't apply to --all.
+func (lf *ListFlags) Pages(l ListFlagsSetter, fetch func() (int, error)) error {
+	if !lf.All {
+		lf.Apply(l)
+		_, err := fetch()
+		return err
+	}
+	maxResults := lf.Limit
+	if lf.limitFlag != nil && !lf.limitFlag.Changed {
+		maxResults = 0
+	}
+	pageSize := lf.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	l.SetOrdering(&lf.Order)
+	offset, total := lf.Offset, 0
+	for {
+		size := pageSize
+		if maxResults > 0 && maxResults-total < size {
+			size = maxResults - total
+		}
+		limit, pageOffset := strconv.Itoa(size), strconv.Itoa(offset)
+		l.SetLimit(&limit)
+		l.SetOffset(&pageOffset)
+		n, err := fetch()
+		if err != nil {
+			return err
+		}
+		total += n
+		offset += n
+		// Shorter page is the last one, longer means the backend doesn't paginate.
+		if n != size || (maxResults > 0 && total >= maxResults) {
+			return nil
+		}
+	}
+}
+
 func (lf *ListFlags) Set(cmd *cobra.Command) {
 	defaultLimit := viper.GetInt("limit")
 	cmd.Flags().IntVar(&lf.Limit, "limit", defaultLimit, "Limit list results")
+	lf.limitFlag = cmd.Flags().Lookup("limit")
 	cmd.Flags().IntVar(&lf.Offset, "offset", 0, "Offset list results")
-	cmd.Flags().StringVar(&lf.Order, "order", "", "Output order")
+	cmd.Flags().StringVar(&lf.Order, "order", "", "Fields the backend orders results by, prefix with - for descending, e.g. -created")
+	cmd.Flags().StringVar(&lf.SortBy, "sort-by", "", "Sort results by comma separated field paths, prefix with - for descending, e.g. status,-created")
+	cmd.Flags().BoolVar(&lf.All, "all", false, "Fetch all pages of results")
+	cmd.Flags().IntVar(&lf.PageSize, "page-size", DefaultPageSize, "Number of results fetched per request with --all")
 }
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type pageParams struct {
+	limit, offset int
+}
+
+func (p *pageParams) SetLimit(limit *string) {
+	p.limit, _ = strconv.Atoi(*limit)
+}
+
+func (p *pageParams) SetOffset(offset *string) {
+	p.offset, _ = strconv.Atoi(*offset)
+}
+
+func (p *pageParams) SetOrdering(*string) {}
+
+func TestListFlagsPages(t *testing.T) {
+	tests := []struct {
+		flags    ListFlags
+		total    int
+		expected [][2]int
+	}{
+		{ListFlags{Limit: 10}, 250, [][2]int{{10, 0}}},
+		{ListFlags{All: true}, 250, [][2]int{{100, 0}, {100, 100}, {100, 200}}},
+		{ListFlags{All: true, PageSize: 50}, 100, [][2]int{{50, 0}, {50, 50}, {50, 100}}},
+		{ListFlags{All: true, PageSize: 50, Limit: 70, Offset: 5}, 250, [][2]int{{50, 5}, {20, 55}}},
+	}
+	for _, test := range tests {
+		params := &pageParams{}
+		var pages [][2]int
+		err := test.flags.Pages(params, func() (int, error) {
+			pages = append(pages, [2]int{params.limit, params.offset})
+			n := test.total - params.offset
+			if n > params.limit && params.limit > 0 {
+				n = params.limit
+			}
+			if n < 0 {
+				n = 0
+			}
+			return n, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(pages, test.expected) {
+			t.Errorf("Wrong pages for %+v: %v", test.flags, pages)
+		}
+	}
+}
+
+func TestListFlagsPagesUnpaginated(t *testing.T) {
+	lf := &ListFlags{All: true, PageSize: 10}
+	calls := 0
+	err := lf.Pages(&pageParams{}, func() (int, error) {
+		calls++
+		return 25, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("Backend without pagination should be called once, got %d calls", calls)
+	}
+}
+
+func TestListFlagsAllIgnoresConfigLimit(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("limit", 20)
+	lf := &ListFlags{}
+	cmd := &cobra.Command{}
+	lf.Set(cmd)
+	lf.All = true
+	fetch := func(params *pageParams) func() (int, error) {
+		return func() (int, error) {
+			if params.offset >= 250 {
+				return 0, nil
+			}
+			return params.limit, nil
+		}
+	}
+	params := &pageParams{}
+	if err := lf.Pages(params, fetch(params)); err != nil {
+		t.Fatal(err)
+	}
+	if params.offset != 300 {
+		t.Errorf("Config limit shouldn't cap --all, last page offset is %d", params.offset)
+	}
+	cmd.Flags().Set("limit", "120")
+	params = &pageParams{}
+	if err := lf.Pages(params, fetch(params)); err != nil {
+		t.Fatal(err)
+	}
+	if params.limit != 20 || params.offset != 100 {
+		t.Errorf("--limit should cap --all, last page is %+v", params)
+	}
+}